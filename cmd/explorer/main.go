@@ -4,6 +4,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -26,6 +27,8 @@ func main() {
 	// get command line flags
 	confPath := flag.String("c", "", "flag to get configuration from json file")
 	monitor := flag.Bool("m", false, "flag to monitor the server with Prometheus at http://localhost:9090")
+	admin := flag.String("a", "", "flag to serve the admin API on the address given (ie. :9200 on localhost)")
+	lease := flag.Int("l", 0, "flag to explore each network only while holding its lease of the seconds given (ie. 15)")
 	flag.Parse()
 
 	// extract configuration
@@ -48,6 +51,13 @@ func main() {
 	}
 	defer db.Close(conf.DBType, dbConn)
 
+	// add or remove the networks added or removed at runtime
+	if dbConn != nil {
+		if conf.Bc, err = store.Networks(dbConn, conf.Bc); err != nil {
+			panic(err)
+		}
+	}
+
 	// load all blockchains
 	blocks, err := block.Init(conf.Bc)
 	if err != nil {
@@ -95,6 +105,22 @@ func main() {
 	// create explorer service
 	e := explorer.New(conf.DBType, dbConn, mb, blocks)
 
+	// elect a leader per network among the explorers sharing the database, the others wait on standby
+	if *lease > 0 && dbConn != nil {
+		host, _ := os.Hostname()
+		e.Lease(fmt.Sprintf("%s-%d", host, os.Getpid()), time.Duration(*lease)*time.Second)
+	}
+
+	// load admin API
+	if *admin != "" {
+		go func() {
+			log.Printf("Serving admin API on %s", *admin)
+
+			err := e.Admin(*admin).ListenAndServe()
+			log.Printf("Admin API server:%e", err)
+		}()
+	}
+
 	// capture CTRL+C or docker's SIGTERM for gracious exit
 	go func() {
 		sigchan := make(chan os.Signal, 10)
@@ -53,6 +53,13 @@ func main() {
 		log.Printf("Connecting to database:%+v\n", conf.DBConn)
 	}
 
+	// add or remove the networks added or removed at runtime
+	if dbConn != nil {
+		if conf.Bc, err = store.Networks(dbConn, conf.Bc); err != nil {
+			panic(err)
+		}
+	}
+
 	// load all blockchains
 	blocks, err := block.Init(conf.Bc)
 	if err != nil {
@@ -25,12 +25,19 @@ interface.
 A blockchain layer (package lib/block) is implemented so new blockchain interfaces can be developed and added. The
 layer provides basic functionality to request account balance, send and get transactions, etc. Both the wallet and
 explorer services will connect to the blockchains or networks indicated in the JSON config file provided at startup.
+Ethereum networks (package lib/block/ethereum) and Bitcoin networks (package lib/block/bitcoin) are supported, and any
+EVM network is added by configuration with its chain type "evm", chain id and native currency. The
+transactions of Bitcoin blocks are explored per output, so the deposits to monitored addresses are detected, and are
+sent spending the unspent outputs of the HD wallet addresses with the change sent to the HD wallet's change branch.
 
 Depending on workload and resources, one or more instances of the microservices can be orchestrated in order to provide
 the required service level to the users.
 
 The microservices can also be monitored via a Prometheus API by setting the flag "-m" at startup.
 
+Networks can be added or removed at runtime with the wallet API, which sends a request to the explorers through the
+message broker. The networks added or removed are saved to the database, so they survive restarts.
+
 Wallet
 
 The wallet microservice (package wallet) can be started running cmd/wallet/main.go or using Dockerfile.wallet. The
@@ -47,6 +54,31 @@ The explorer microservice (package explorer) can be started running cmd/explorer
 The explorer scans mined blocks of the configured networks and sends transaction events to the message broker when an
 account or address being monitored is involved. Wallet services can send requests for the explorer to start or stop
 monitoring addresses so that real time eventing can be provided to the clients or front-end.
+Each monitored object has a subscription record (owner, label, direction and client metadata) that is echoed in the
+"subs" field of the events it triggers, so consumers know which subscription fired. Address subscriptions can filter
+the transactions that trigger events by direction, token contracts (or native currency only) and minimum value. They
+can also expire after a time, when the address stops being monitored and optionally an "expired" event is sent.
+Token transfers are detected from the Transfer logs of the blocks, so the tokens transferred through any contract are
+detected, and are sent as events of kind "token".
+If the "trace" option is set for a network, the ether transferred by contracts is detected from the call traces of the
+blocks and sent as events of kind "internal".
+Transactions can also be monitored by their hash: the explorer sends a "seen" event (or "failed" if its execution
+failed) when the transaction is mined, a "confirmed" event when its block has the confirmations configured for the
+network, and a "dropped" event if it has not been mined before the timeout requested.
+Contract creations sent by monitored addresses are sent as "created" events (instead of "seen") with the address of
+the new contract in the "contract" field.
+When a chain reorganization orphans a block, the explorer sends a "removed" event for each transaction event already
+sent for that block, and a "reemitted" event if the transaction is later included in a block of the canonical chain.
+Both events inform the hash of the orphaned block ("removedFrom") and of the block that replaced it ("replacedBy").
+The explorer serves an admin HTTP API when started with the flag "-a <address>". It replies the status of each network
+(last block explored, head block, lag, status, last error and number of addresses and transactions monitored) and
+allows to pause, resume, restart and move the explorer of a network without restarting the service.
+Explorers can also be controlled through the message broker with wallet requests of type CTRL (see package lib/msg),
+ie. sent by the wallet API: pause, resume, rewind to a block, rescan a range of blocks, reload the monitored addresses
+and transactions from the database, or exit.
+Explorer replicas sharing the database can be run as hot standbys with the flag "-l <seconds>": each network is only
+explored by the replica holding its lease in the database, and the others take it over when the lease expires or is
+released. The fencing token of the lease is saved with the explorer status so a former leader cannot overwrite it.
 
 */
 package adp
@@ -0,0 +1,198 @@
+package explorer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tarancss/adp/lib/config"
+)
+
+// adminTimeout is the number of seconds the admin server waits to read requests and write responses. Restart may
+// take up to restartTimeout.
+const adminTimeout = 15
+
+// Errors returned to admin requests.
+var (
+	ErrBadBlock   = errors.New("invalid block: has to be a block number - missing query: ?block=<number>")
+	ErrBadNetwork = errors.New("invalid network: has to be a JSON blockchain configuration with a name")
+)
+
+// Response defines the data structure returned to the client making an admin http request.
+type Response struct {
+	Body  interface{} `json:"body,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// Admin returns an http server for the admin API of the explorer, listening on 'addr' (ie. ":9200"). The API is not
+// authenticated, so it listens on the loopback interface unless the host is given (ie. "0.0.0.0:9200"). The API replies
+// the status of the exploration of the networks and allows to pause, resume, restart and move the explorer of a
+// network without restarting the service. Networks can also be added and removed:
+//
+//	GET    /status             status of all the networks explored
+//	GET    /status/{net}       status of the network
+//	POST   /pause/{net}        pauses the exploration of the network
+//	POST   /resume/{net}       resumes the exploration of the network
+//	POST   /restart/{net}      restarts the go routine exploring the network (ie. after it stopped on an error)
+//	POST   /seek/{net}?block=N moves the explorer so the next block explored is N+1
+//	POST   /networks           adds the network configured in the JSON body (see config.BlockConfig)
+//	DELETE /networks/{net}     removes the network
+func (e *Explorer) Admin(addr string) *http.Server {
+	r := mux.NewRouter()
+	r.HandleFunc("/status", e.statusHandler).Methods("GET")
+	r.HandleFunc("/status/{net}", e.statusHandler).Methods("GET")
+	r.HandleFunc("/pause/{net}", e.controlHandler(e.Pause)).Methods("POST")
+	r.HandleFunc("/resume/{net}", e.controlHandler(e.Resume)).Methods("POST")
+	r.HandleFunc("/restart/{net}", e.controlHandler(e.Restart)).Methods("POST")
+	r.HandleFunc("/seek/{net}", e.seekHandler).Methods("POST")
+	r.HandleFunc("/networks", e.addNetworkHandler).Methods("POST")
+	r.HandleFunc("/networks/{net}", e.removeNetworkHandler).Methods("DELETE")
+
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+
+	return &http.Server{
+		Handler:      r,
+		Addr:         addr,
+		WriteTimeout: restartTimeout + adminTimeout*time.Second,
+		ReadTimeout:  adminTimeout * time.Second,
+	}
+}
+
+// reply writes the response to an admin request with status code 'code'.
+func reply(rw http.ResponseWriter, r *http.Request, code int, body interface{}, err error) {
+	res := Response{Body: body}
+	if err != nil {
+		res.Error = fmt.Sprintf("%s", err)
+	}
+
+	log.Printf("adminreq from %v %s %s code:%d err:%e\n", r.RemoteAddr, r.Method, r.RequestURI, code, err)
+
+	rw.Header().Set("Content-Type", "application/json;charset=utf8")
+	rw.WriteHeader(code)
+	_ = json.NewEncoder(rw).Encode(&res)
+}
+
+// statusHandler replies the status of the network requested, or of all the networks explored.
+func (e *Explorer) statusHandler(rw http.ResponseWriter, r *http.Request) {
+	if net, ok := mux.Vars(r)["net"]; ok {
+		st, err := e.Status(net)
+		if err != nil {
+			reply(rw, r, http.StatusNotFound, nil, err)
+
+			return
+		}
+
+		reply(rw, r, http.StatusOK, st, nil)
+
+		return
+	}
+
+	reply(rw, r, http.StatusOK, e.Statuses(), nil)
+}
+
+// controlHandler returns a handler applying 'f' to the network requested and replying its status.
+func (e *Explorer) controlHandler(f func(net string) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		net := mux.Vars(r)["net"]
+
+		if err := f(net); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrUnknownNet) {
+				code = http.StatusNotFound
+			}
+
+			reply(rw, r, code, nil, err)
+
+			return
+		}
+
+		st, err := e.Status(net)
+		reply(rw, r, http.StatusOK, st, err)
+	}
+}
+
+// seekHandler moves the explorer of the network requested to the block given in the query.
+func (e *Explorer) seekHandler(rw http.ResponseWriter, r *http.Request) {
+	block, err := strconv.ParseUint(r.URL.Query().Get("block"), 10, 64)
+	if err != nil {
+		reply(rw, r, http.StatusBadRequest, nil, ErrBadBlock)
+
+		return
+	}
+
+	e.controlHandler(func(net string) error { return e.Seek(net, block) })(rw, r)
+}
+
+// addNetworkHandler adds the network configured in the body of the request and replies its status.
+func (e *Explorer) addNetworkHandler(rw http.ResponseWriter, r *http.Request) {
+	var conf config.BlockConfig
+
+	if err := json.NewDecoder(r.Body).Decode(&conf); err != nil || conf.Name == "" {
+		reply(rw, r, http.StatusBadRequest, nil, ErrBadNetwork)
+
+		return
+	}
+
+	if err := e.AddNetwork(conf); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, ErrNetExists) {
+			code = http.StatusConflict
+		}
+
+		reply(rw, r, code, nil, err)
+
+		return
+	}
+
+	st, err := e.Status(conf.Name)
+	reply(rw, r, http.StatusCreated, st, err)
+}
+
+// removeNetworkHandler removes the network requested.
+func (e *Explorer) removeNetworkHandler(rw http.ResponseWriter, r *http.Request) {
+	if err := e.RemoveNetwork(mux.Vars(r)["net"]); err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, ErrUnknownNet) {
+			code = http.StatusNotFound
+		}
+
+		reply(rw, r, code, nil, err)
+
+		return
+	}
+
+	reply(rw, r, http.StatusOK, nil, nil)
+}
+
+// Statuses returns the status of the exploration of all the networks explored, sorted by network name.
+func (e *Explorer) Statuses() []Status {
+	e.nl.RLock()
+	nets := make([]string, 0, len(e.nem))
+
+	for net := range e.nem {
+		nets = append(nets, net)
+	}
+	e.nl.RUnlock()
+
+	sort.Strings(nets)
+
+	sts := make([]Status, 0, len(nets))
+
+	for _, net := range nets {
+		if st, err := e.Status(net); err == nil {
+			sts = append(sts, st)
+		}
+	}
+
+	return sts
+}
@@ -0,0 +1,224 @@
+package explorer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ne "github.com/tarancss/adp/explorer/netexplorer"
+	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/msg"
+	"github.com/tarancss/adp/lib/store"
+)
+
+// adminChain is a mock chain at block 'head', only implementing the methods used by the admin API and ExploreChain
+// when there is nothing to explore.
+type adminChain struct {
+	fetchChain
+}
+
+func (c *adminChain) Latest() (uint64, error)         { return c.head, nil }
+func (c *adminChain) AvgBlock() (time.Duration, bool) { return time.Second, true }
+func (c *adminChain) Close()                          {}
+
+// adminDB is a mock database without explorers saved.
+type adminDB struct {
+	store.DB
+	nets []store.Network
+}
+
+func (d *adminDB) LoadExplorer(net string) (store.NetExplorer, error) {
+	return store.NetExplorer{}, store.ErrDataNotFound
+}
+
+func (d *adminDB) SaveExplorer(net string, n store.NetExplorer) error { return nil }
+
+func (d *adminDB) SaveNetwork(n store.Network) error {
+	d.nets = append(d.nets, n)
+
+	return nil
+}
+
+func (d *adminDB) GetAddresses(net []string) ([]store.ListenedAddresses, error) {
+	return []store.ListenedAddresses{{Net: net[0], Addr: []store.Address{{Addr: "0x01"}, {Addr: "0x02"}}}}, nil
+}
+
+func (d *adminDB) GetTxs(net []string) ([]store.ListenedTxs, error) {
+	return []store.ListenedTxs{{Net: net[0], Txs: []store.Tx{{Hash: "0x03"}}}}, nil
+}
+
+// TestAdmin tests the admin API replies the status of the networks, and pauses, resumes, moves and restarts them.
+func TestAdmin(t *testing.T) {
+	c := &adminChain{fetchChain{head: 120}}
+	e := New("", &adminDB{}, nil, map[string]block.Chain{"ropsten": c})
+
+	var err error
+	if e.nem["ropsten"], err = ne.New("ropsten", 12, func() (uint64, error) { return 101, nil }, nil, nil,
+		e.db); err != nil {
+		t.Fatalf("netexplorer.New err:%e", err)
+	}
+
+	h := e.Admin(":0").Handler
+
+	for _, tc := range []struct {
+		name, method, uri string
+		code              int
+		status            string
+		block             uint64
+		running           bool
+	}{
+		{"status", "GET", "/status/ropsten", http.StatusOK, "WORK", 100, false},
+		{"unknown", "GET", "/status/mainnet", http.StatusNotFound, "", 0, false},
+		{"pause", "POST", "/pause/ropsten", http.StatusOK, "PAUSE", 100, false},
+		{"pauseGet", "GET", "/pause/ropsten", http.StatusMethodNotAllowed, "", 0, false},
+		{"restart", "POST", "/restart/ropsten", http.StatusOK, "WORK", 100, true},
+		{"pause2", "POST", "/pause/ropsten", http.StatusOK, "PAUSE", 100, true},
+		{"restart2", "POST", "/restart/ropsten", http.StatusOK, "WORK", 100, true},
+		{"seekBad", "POST", "/seek/ropsten?block=x", http.StatusBadRequest, "", 0, false},
+		{"seekUnknown", "POST", "/seek/mainnet?block=1", http.StatusNotFound, "", 0, false},
+		{"pause3", "POST", "/pause/ropsten", http.StatusOK, "PAUSE", 100, true},
+		{"seek", "POST", "/seek/ropsten?block=110", http.StatusOK, "PAUSE", 100, true},
+		{"resume", "POST", "/resume/ropsten", http.StatusOK, "WORK", 100, true},
+		{"resumeUnknown", "POST", "/resume/mainnet", http.StatusNotFound, "", 0, false},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.uri, nil))
+
+		if rec.Code != tc.code {
+			t.Errorf("%s: code %d, expected %d", tc.name, rec.Code, tc.code)
+
+			continue
+		}
+
+		if tc.code != http.StatusOK {
+			continue
+		}
+
+		var res struct {
+			Body  Status `json:"body"`
+			Error string `json:"error"`
+		}
+		if err = json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Errorf("%s: cannot decode response %s err:%e", tc.name, rec.Body.String(), err)
+
+			continue
+		}
+
+		if st := res.Body; st.Net != "ropsten" || st.Status != tc.status || st.Block != tc.block ||
+			st.Head != 120 || st.Lag != 20 || st.Running != tc.running || res.Error != "" {
+			t.Errorf("%s: status %+v, expected %s block %d running %t", tc.name, res, tc.status, tc.block, tc.running)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+
+	var res struct {
+		Body []Status `json:"body"`
+	}
+	if err = json.Unmarshal(rec.Body.Bytes(), &res); err != nil || len(res.Body) != 1 || res.Body[0].Net != "ropsten" {
+		t.Errorf("status of all networks:%s err:%e", rec.Body.String(), err)
+	}
+	// the admin API is only served on the loopback interface unless the host is given
+	if a, b := e.Admin(":9200").Addr, e.Admin("0.0.0.0:9200").Addr; a != "127.0.0.1:9200" || b != "0.0.0.0:9200" {
+		t.Errorf("admin API served on %s and %s", a, b)
+	}
+
+	e.StopExplorer()
+	e.wg.Wait()
+}
+
+// TestControl tests the control commands of wallet requests are applied to the network explorer.
+func TestControl(t *testing.T) {
+	c := &adminChain{fetchChain{head: 120}}
+	e := New("", &adminDB{}, nil, map[string]block.Chain{"ropsten": c})
+
+	var err error
+	if e.nem["ropsten"], err = ne.New("ropsten", 12, func() (uint64, error) { return 101, nil }, nil, nil,
+		e.db); err != nil {
+		t.Fatalf("netexplorer.New err:%e", err)
+	}
+
+	nexp := e.nem["ropsten"]
+
+	for _, tc := range []struct {
+		name   string
+		req    msg.WalletReq
+		err    error
+		status int
+		block  uint64
+	}{
+		{"pause", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdPause}, nil, ne.PAUSE, 100},
+		{"wrongNet", msg.WalletReq{Net: "mainnet", Type: msg.CTRL, Obj: msg.CmdResume}, ErrCommand, ne.PAUSE, 100},
+		{"resume", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdResume}, nil, ne.WORK, 100},
+		{"unknown", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: "jump"}, ErrCommand, ne.WORK, 100},
+		{"rewind0", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdRewind}, ErrCommand, ne.WORK, 100},
+		{"rewind", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdRewind, From: 50}, nil, ne.WORK, 49},
+		{"rescanBad", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdRescan, From: 9, To: 8}, ErrCommand,
+			ne.WORK, 49},
+		{"rescan", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdRescan, From: 30, To: 31}, nil, ne.WORK, 29},
+		{"reload", msg.WalletReq{Net: "ropsten", Type: msg.CTRL, Obj: msg.CmdReload}, nil, ne.WORK, 29},
+		{"exit", msg.WalletReq{Net: "ropsten", Type: msg.EXIT, Obj: msg.CmdExit}, nil, ne.STOP, 29},
+	} {
+		if err = e.control("ropsten", tc.req); !errors.Is(err, tc.err) {
+			t.Errorf("%s: err:%e, expected:%e", tc.name, err, tc.err)
+		}
+
+		if nexp.Status() != tc.status {
+			t.Errorf("%s: status %d, expected %d", tc.name, nexp.Status(), tc.status)
+		}
+
+		if b, _ := nexp.Move(); b != tc.block {
+			t.Errorf("%s: block %d, expected %d", tc.name, b, tc.block)
+		}
+	}
+
+	if addrs, txs := nexp.Monitored(); addrs != 2 || txs != 1 {
+		t.Errorf("reload monitors %d addresses and %d txs", addrs, txs)
+	}
+}
+
+// TestNetworks tests networks are removed and the configuration of the networks added is validated.
+func TestNetworks(t *testing.T) {
+	c := &adminChain{fetchChain{head: 120}}
+	db := &adminDB{}
+	e := New("", db, nil, map[string]block.Chain{"ropsten": c})
+
+	var err error
+	if e.nem["ropsten"], err = ne.New("ropsten", 12, nil, nil, nil, e.db); err != nil {
+		t.Fatalf("netexplorer.New err:%e", err)
+	}
+
+	h := e.Admin(":0").Handler
+
+	for _, tc := range []struct {
+		name, method, uri, body string
+		code                    int
+	}{
+		{"exists", "POST", "/networks", `{"name":"ropsten","maxBlocks":8}`, http.StatusConflict},
+		{"badJSON", "POST", "/networks", `{"name":`, http.StatusBadRequest},
+		{"noName", "POST", "/networks", `{"maxBlocks":8}`, http.StatusBadRequest},
+		{"badConf", "POST", "/networks", `{"name":"rinkeby","maxBlocks":8,"confirmations":8}`, http.StatusBadRequest},
+		{"noInterface", "POST", "/networks", `{"name":"goerli","maxBlocks":8}`, http.StatusBadRequest},
+		{"queueName", "POST", "/networks", `{"name":"networks","type":"evm","maxBlocks":8}`, http.StatusBadRequest},
+		{"badName", "POST", "/networks", `{"name":"main.net","type":"evm","maxBlocks":8}`, http.StatusBadRequest},
+		{"remove", "DELETE", "/networks/ropsten", "", http.StatusOK},
+		{"removed", "GET", "/status/ropsten", "", http.StatusNotFound},
+		{"removeAgain", "DELETE", "/networks/ropsten", "", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.uri, strings.NewReader(tc.body)))
+
+		if rec.Code != tc.code {
+			t.Errorf("%s: code %d, expected %d, body:%s", tc.name, rec.Code, tc.code, rec.Body.String())
+		}
+	}
+
+	if len(db.nets) != 1 || db.nets[0].Conf.Name != "ropsten" || !db.nets[0].Removed {
+		t.Errorf("networks saved:%+v", db.nets)
+	}
+}
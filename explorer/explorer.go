@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 
@@ -23,6 +24,19 @@ type Explorer struct {
 	bc     map[string]block.Chain     // map of blockchain clients
 	nem    map[string]*ne.NetExplorer // map of net explorers
 	mb     msg.MsgBroker
+
+	nl  sync.RWMutex         // nl is a mutex to ensure concurrent access to bc and nem, networks can be added or removed
+	req map[string]bool      // networks whose wallet requests are being consumed
+	l   sync.Mutex           // l is a mutex to ensure concurrent access to the runtime state of the networks
+	st  map[string]*netState // runtime state of the networks explored (see Status)
+	rl  sync.Mutex           // rl serializes restarts and networks added or removed
+	wg  sync.WaitGroup       // go routines exploring networks
+	ret chan string          // channel where the go routines exploring networks return
+	run bool                 // Explore is running until StopExplorer is called
+
+	owner string        // owner of the leases acquired (see Lease)
+	ttl   time.Duration // time to live of the leases, 0 if leases are not used
+	quit  chan struct{} // closed by StopExplorer to release the leases
 }
 
 // New instantiates a new explorer service.
@@ -33,103 +47,232 @@ func New(dbtype string, db store.DB, mb msg.MsgBroker, bc map[string]block.Chain
 		bc:     bc,
 		nem:    make(map[string]*ne.NetExplorer),
 		mb:     mb,
+		req:    make(map[string]bool),
+		st:     make(map[string]*netState),
+		ret:    make(chan string, len(bc)),
 	}
 }
 
 // Explore starts a go routine for each network available. The exploration of each network is controlled by a
-// NetExplorer (see package explorer/netexplorer for details) and contains a map of the addresses being monitored and
-// the current status of scanned blocks. The explorer consumes wallet requests to monitor new addresses. In case of
-// graceful termination, the explorer will wait for all the blocks being scanned to finish and sending the events if
-// any.
+// NetExplorer (see package explorer/netexplorer for details) and contains maps of the addresses and transactions being
+// monitored and the current status of scanned blocks. The explorer consumes wallet requests to monitor new addresses
+// and transactions, and to add or remove networks at runtime. In case of graceful termination, the explorer will wait
+// for all the blocks being scanned to finish and sending the events if any.
 func (e *Explorer) Explore() chan string {
 	ret := make(chan string, 1)
-	// channel to wait for chain explorers
-	w := make(chan string, len(e.bc))
 
-	for net := range e.bc {
-		// get listened addresses from DB
-		addrs, err := e.db.GetAddresses([]string{net})
-		if err != nil {
-			log.Printf("[%s] Cannot load listened addresses from DB, err:%e", net, err)
+	// Explore returns once StopExplorer is called, even if no networks are explored
+	e.wg.Add(1)
 
-			continue
-		}
+	e.nl.Lock()
+	e.run = true
+	bc := make(map[string]block.Chain, len(e.bc))
 
-		if len(addrs) == 0 || len(addrs[0].Addr) == 0 {
-			log.Printf("[%s] No listened addresses to explore in DB.", net)
-		}
-		// set listened address map
-		// TODO: extra functionality - monitor transactions!!!
-		if e.nem[net], err = ne.New(net, e.bc[net].MaxBlocks(), addrs, e.db); err != nil {
-			log.Printf("[%s] netexplorer.New failed:%e", net, err)
+	for net, c := range e.bc {
+		bc[net] = c
+	}
+	e.nl.Unlock()
 
-			continue
+	for net, c := range bc {
+		if err := e.explore(net, c); err != nil {
+			log.Printf("[%s] Cannot explore network, err:%e", net, err)
 		}
-		// listen for wallet requests, if there are pending requests in the broker queues, they will be processed to DB
-		// so getAddresses starts with all the data loaded
-		if err = e.ManageWalletRequests(net); err != nil {
-			log.Printf("[%s] Cannot consume wallet requests from broker, err:%e", net, err)
+	}
 
-			continue
-		}
-		// Explore
-		e.ExploreChain(net, w)
+	if err := e.ManageNetworkRequests(); err != nil {
+		log.Printf("Cannot consume network requests from broker, err:%e", err)
+	}
+	// the networks are explored by the explorer holding their leases
+	if e.ttl > 0 {
+		e.wg.Add(1)
+
+		go e.lead()
 	}
-	// routine to wait for all chains to complete exploring...
+	// routines to wait for all chains to complete exploring, including the ones restarted or added...
 	go func() {
-		for i := 1; i < len(e.bc)+1; i++ {
-			log.Printf("Explore, channel %d/%d returned: %s", i, len(e.bc), <-w)
+		for r := range e.ret {
+			log.Printf("Explore, network explorer returned: %s", r)
 		}
+	}()
+
+	go func() {
+		e.wg.Wait()
 		ret <- "Done!"
 	}()
 
 	return ret
 }
 
+// explore starts exploring blockchain named 'net' with client 'c', which has to be in bc. The monitored addresses and
+// transactions are loaded from DB and its wallet requests are consumed. If leases are used, the network is explored
+// once its lease is acquired.
+func (e *Explorer) explore(net string, c block.Chain) error {
+	// get listened addresses from DB
+	addrs, err := e.db.GetAddresses([]string{net})
+	if err != nil {
+		return fmt.Errorf("explorer: cannot load listened addresses from DB: %w", err)
+	}
+
+	if len(addrs) == 0 || len(addrs[0].Addr) == 0 {
+		log.Printf("[%s] No listened addresses to explore in DB.", net)
+	}
+	// get listened transactions from DB
+	txs, err := e.db.GetTxs([]string{net})
+	if err != nil {
+		return fmt.Errorf("explorer: cannot load listened transactions from DB: %w", err)
+	}
+	// set listened address and transaction maps, a new network is explored from its configured start block
+	start := func() (uint64, error) { return block.Start(c) }
+
+	nexp, err := ne.New(net, c.MaxBlocks(), start, addrs, txs, e.db)
+	if err != nil {
+		return fmt.Errorf("explorer: netexplorer.New failed: %w", err)
+	}
+
+	e.nl.Lock()
+	e.nem[net] = nexp
+
+	if e.ttl > 0 {
+		e.nl.Unlock()
+
+		return nil
+	}
+
+	consuming := e.req[net]
+	e.req[net] = true
+	e.nl.Unlock()
+	// listen for wallet requests, if there are pending requests in the broker queues, they will be processed to DB
+	// so getAddresses starts with all the data loaded. The requests of a network removed and added again are still
+	// being consumed.
+	if !consuming {
+		if err = e.ManageWalletRequests(net); err != nil {
+			e.nl.Lock()
+			delete(e.nem, net)
+			delete(e.req, net)
+			e.nl.Unlock()
+
+			return fmt.Errorf("explorer: cannot consume wallet requests from broker: %w", err)
+		}
+	}
+	// Explore
+	e.ExploreChain(net, e.ret)
+
+	return nil
+}
+
+// network returns the network explorer and the client of blockchain named 'net', 'ok' is false if it is not explored.
+func (e *Explorer) network(net string) (nexp *ne.NetExplorer, c block.Chain, ok bool) {
+	e.nl.RLock()
+	defer e.nl.RUnlock()
+
+	nexp, c = e.nem[net], e.bc[net]
+
+	return nexp, c, nexp != nil && c != nil
+}
+
+// ErrUnknownNet is returned when a network is not being explored.
+var ErrUnknownNet = errors.New("explorer: network is not being explored")
+
+// Seek moves the explorer of blockchain named 'net' forward or backward so the next block explored is block+1. The
+// blocks kept to check the chain is chained are reset. The move is applied safely by the network explorer go routine
+// before it explores the next block.
+func (e *Explorer) Seek(net string, number uint64) error {
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	nexp.Seek(number)
+
+	return nil
+}
+
 // StopExplorer will send termination signals to all network explorer go routines.
 func (e *Explorer) StopExplorer() {
+	e.nl.Lock()
+	defer e.nl.Unlock()
+
 	for _, nexp := range e.nem {
 		nexp.Stop()
 	}
+
+	if e.run {
+		e.run = false
+		e.wg.Done()
+
+		if e.quit != nil {
+			close(e.quit)
+		}
+	}
 }
 
 // ExploreChain starts a network explorer go routine for blockchain named 'net'. When the routine ends, returns its
 // error status via the 'ret' channel given so the calling routine can control graceful termination. When a network
-// does not have any monitored addresses, the explorer will keep waiting and will not scan any mined blocks.
+// does not have any monitored addresses or transactions, the explorer will keep waiting and will not scan any mined
+// blocks. Once the chain head is reached, the explorer waits for the next block according to the block interval of
+// the network, configured or estimated from the blocks explored (see pacer).
 func (e *Explorer) ExploreChain(net string, ret chan string) {
-	nexp := e.nem[net]
+	nexp, c, _ := e.network(net)
 
 	log.Printf("[%s] Exploring at block %d... ", net, nexp.Block)
 
+	e.started(net)
+
 	go func() {
 		var err error
 
-		c := e.bc[net]
+		f := newFetcher(c, nexp.Block+1)
+		defer f.close()
+
+		p := newPacer(c.AvgBlock())
+		e.paced(net, p.interval())
 
 		defer func() {
 			// save NetExplorer to DB
 			errSave := e.db.SaveExplorer(net, nexp.ToStore())
+			e.ended(net, err)
 			// write into channel
 			ret <- "[" + net + "] Done!" + fmt.Sprintf(" err:%e", err) + fmt.Sprintf(" err2:%e", errSave)
 		}()
 
-		for nexp.Status() == ne.WORK {
-			if len(nexp.Map) == 0 {
+		for nexp.Status() != ne.STOP {
+			if nexp.Status() == ne.PAUSE || !e.leading(net) {
+				// paused or the lease has not been renewed in time, wait to resume or step down
+				time.Sleep(time.Second)
+
+				continue
+			}
+			// apply any move of the explorer requested
+			if b, ok := nexp.Move(); ok {
+				log.Printf("[%s] Explorer moved to block %d", net, b)
+				f.reset(b + 1)
+			}
+
+			e.expire(net)
+			e.drop(net)
+
+			if addrs, txs := nexp.Monitored(); addrs == 0 && txs == 0 {
 				// wait until there is something to explore for
 				log.Printf("[%s] Waiting for something to explore", net)
-				time.Sleep(time.Duration(c.AvgBlock()) * time.Second)
+				time.Sleep(p.interval())
+
+				continue
+			}
+			// get next block's data, the fetcher has to be reset if the explorer has been rewound
+			res := f.get()
+			if res.n != nexp.Block+1 {
+				f.reset(nexp.Block + 1)
 
 				continue
 			}
-			// get next block's data
-			var b map[string]interface{}
 
-			time.Sleep(1 * time.Second) // limit rate at max. 1 block per second!
+			b := res.b
 
-			if err = c.GetBlock(nexp.Block+1, true, &b); err != nil {
+			if err = res.err; err != nil {
 				if errors.Is(err, types.ErrNoBlock) {
 					// lets wait for a new block to be mined
-					time.Sleep(time.Duration(c.AvgBlock()) * time.Second)
+					e.head(net, nexp.Block)
+					time.Sleep(p.wait(time.Now()))
 
 					continue
 				} else {
@@ -140,37 +283,93 @@ func (e *Explorer) ExploreChain(net string, ret chan string) {
 				}
 			}
 			// decode Hash
-			blk, err := c.DecodeBlock(b)
-			if err != nil {
+			var blk types.Block
+			if blk, err = c.DecodeBlock(b); err != nil {
+				log.Printf("[%s] Cannot decode block %d, err:%e", net, nexp.Block+1, err)
+				e.fail(net, err)
+				nexp.Stop()
+
 				return
 			}
 
 			log.Printf("[%s] Parsing block %d hash:%s pHash:%s", net, nexp.Block+1, blk.Hash, blk.PHash)
-			// check block is chained
+			// check block is chained, otherwise recover from the chain reorganization and re-scan the new blocks
 			if !nexp.Chained(blk.PHash) {
-				// TODO: extra functionality FindLastSync
-				log.Printf("[%s] Block %d is not chained!!\n", net, nexp.Block+1)
+				log.Printf("[%s] Block %d is not chained, looking for the common ancestor", net, nexp.Block+1)
+
+				if errReorg := e.Reorg(net); errReorg != nil {
+					log.Printf("[%s] Reorg err:%e", net, errReorg)
+					e.fail(net, errReorg)
+
+					if !errors.Is(errReorg, ne.ErrReorgTooDeep) {
+						// the node could not give us the canonical chain, lets wait before trying again
+						time.Sleep(p.interval())
+					}
+				}
+
+				f.reset(nexp.Block + 1)
+
+				continue
+			}
+
+			// decode transactions and stamp them with the block's timestamp and hash
+			if blk.Tx, err = c.DecodeTxs(b); err != nil {
+				log.Printf("[%s] Cannot decode transactions of block %d, err:%e", net, nexp.Block+1, err)
+				e.fail(net, err)
 				nexp.Stop()
 
 				return
 			}
+			// token transfers are got from the block's logs
+			tr, errTr := c.Transfers(blk)
+			if errTr != nil {
+				log.Printf("[%s] Cannot get token transfers of block %d, err:%e", net, nexp.Block+1, errTr)
+				e.fail(net, errTr)
+				time.Sleep(p.interval())
+				f.reset(nexp.Block + 1)
+
+				continue
+			}
+
+			blk.Tx = append(blk.Tx, tr...)
+
+			var ts int64
+			if ts, err = stamp(blk); err != nil {
+				log.Printf("[%s] Cannot decode timestamp of block %d, err:%e", net, nexp.Block+1, err)
+				e.fail(net, err)
+				nexp.Stop()
 
-			// decode transactions
-			if blk.Tx, err = c.DecodeTxs(b); err != nil {
 				return
 			}
+
+			p.explored(nexp.Block+1, ts)
+			e.paced(net, p.interval())
 			// sync'ed - store hash and update other data
 			nexp.UpdateChain(blk.Hash, c.MaxBlocks())
-			// Scan transactions
+			// Scan transactions, marking the ones seen and the ones that had been removed by a reorg
 			r, _ := nexp.ScanTxs(blk.Tx)
+			r = nexp.Seen(r)
+			e.receipts(net, r)
 			// send events
 			if len(r) > 0 {
 				err = e.mb.SendTrans(net, r)
 				log.Printf("[%s] Sending %d events:%+v err:%e\n", net, len(r), r, err)
 			}
+			// keep the events sent in case the block is orphaned, and send the events of the block now confirmed
+			nexp.Record(r)
+
+			if conf := nexp.Confirm(c.Confirmations()); len(conf) > 0 {
+				err = e.mb.SendTrans(net, conf)
+				log.Printf("[%s] Sending %d confirmed events:%+v err:%e\n", net, len(conf), conf, err)
+				// confirmed transactions do not have to be monitored any longer
+				for _, tx := range conf {
+					e.untrack(net, tx.Hash)
+				}
+			}
 			// save netExplorer status to DB
 			if errSave := e.db.SaveExplorer(net, nexp.ToStore()); errSave != nil {
 				log.Printf("[%s] Error saving NetExplorer to DB, err:%e", net, errSave)
+				err = errSave
 
 				break
 			}
@@ -178,8 +377,255 @@ func (e *Explorer) ExploreChain(net string, ret chan string) {
 	}()
 }
 
-// ManageWalletRequests starts a go routine to receive and manage wallet requests for objects (addresses, ...) to be
-// monitored for the blockchain named 'net'.
+// ErrCommand is returned when a control command is unknown or its blocks are invalid.
+var ErrCommand = errors.New("explorer: invalid control command")
+
+// control applies the control command of a wallet request of type CTRL or EXIT to the explorer of network 'net'.
+func (e *Explorer) control(net string, req msg.WalletReq) error {
+	if req.Net != net {
+		return fmt.Errorf("%w: wrong net %s", ErrCommand, req.Net)
+	}
+
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	if req.Type == msg.EXIT {
+		log.Printf("[%s] Stopping explorer as requested", net)
+		nexp.Stop()
+
+		return nil
+	}
+
+	switch req.Obj {
+	case msg.CmdPause:
+		return e.Pause(net)
+	case msg.CmdResume:
+		return e.Resume(net)
+	case msg.CmdRewind:
+		if req.From == 0 {
+			return fmt.Errorf("%w: rewind requires block 'from'", ErrCommand)
+		}
+
+		return e.Seek(net, req.From-1)
+	case msg.CmdRescan:
+		if req.From == 0 || req.To < req.From {
+			return fmt.Errorf("%w: rescan requires blocks 'from' <= 'to'", ErrCommand)
+		}
+
+		nexp.Rescan(req.From, req.To)
+
+		return nil
+	case msg.CmdReload:
+		addrs, err := e.db.GetAddresses([]string{net})
+		if err != nil {
+			return fmt.Errorf("explorer: cannot load listened addresses: %w", err)
+		}
+
+		txs, err := e.db.GetTxs([]string{net})
+		if err != nil {
+			return fmt.Errorf("explorer: cannot load listened transactions: %w", err)
+		}
+
+		nexp.Reload(addrs, txs)
+		n, m := nexp.Monitored()
+		log.Printf("[%s] Reloaded %d addresses and %d transactions", net, n, m)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrCommand, req.Obj)
+}
+
+// expire stops monitoring the addresses whose subscription has expired, deleting them from DB and sending an EvExpired
+// event for the ones whose subscription requested it.
+func (e *Explorer) expire(net string) {
+	var ev []types.Trans
+
+	nexp, _, _ := e.network(net)
+
+	for _, s := range nexp.Expired(time.Now().Unix()) {
+		if err := e.db.RemoveAddress(store.Address{Addr: s.Obj}, net); err != nil {
+			log.Printf("[%s] Error deleting expired address %s from DB %e", net, s.Obj, err)
+		}
+
+		log.Printf("[%s] Subscription of address %s expired", net, s.Obj)
+
+		if s.Notify {
+			ev = append(ev, types.Trans{Event: types.EvExpired, Subs: []types.Sub{s}})
+		}
+	}
+
+	if len(ev) > 0 {
+		err := e.mb.SendTrans(net, ev)
+		log.Printf("[%s] Sending %d expired events:%+v err:%e\n", net, len(ev), ev, err)
+	}
+}
+
+// drop stops monitoring the transactions not mined before their timeout, deleting them from DB and sending an
+// EvDropped event. The node is asked first, so a transaction mined in a block not kept by the explorer is not dropped.
+func (e *Explorer) drop(net string) {
+	nexp, c, _ := e.network(net)
+
+	drop := nexp.Dropped(time.Now().Unix(), func(hash string) bool {
+		tx, err := c.Get(hash)
+		if err != nil || tx.Status == types.TxPending {
+			return false
+		}
+
+		log.Printf("[%s] Transaction %s was mined in block %s, not dropped", net, hash, tx.Block)
+
+		return true
+	})
+	if len(drop) == 0 {
+		return
+	}
+
+	err := e.mb.SendTrans(net, drop)
+	log.Printf("[%s] Sending %d dropped events:%+v err:%e\n", net, len(drop), drop, err)
+
+	for _, tx := range drop {
+		if errDel := e.db.RemoveTx(store.Tx{Hash: tx.Hash}, net); errDel != nil {
+			log.Printf("[%s] Error deleting dropped transaction %s from DB %e", net, tx.Hash, errDel)
+		}
+	}
+}
+
+// stamp sets the timestamp and hash of the block in its transactions, returning the timestamp.
+func stamp(blk types.Block) (int64, error) {
+	ts, err := strconv.ParseUint(blk.TS, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("explorer: cannot parse block timestamp %s: %w", blk.TS, err)
+	}
+
+	for i := range blk.Tx {
+		blk.Tx[i].TS = uint32(ts)
+		blk.Tx[i].BlockHash = blk.Hash
+	}
+
+	return int64(ts), nil
+}
+
+// receipts loads the status, gas used, effective gas price and fee of the transactions scanned in the last block from
+// their receipts, which are got once for the events of the same transaction. The event type of the monitored
+// transactions seen whose execution failed is set to EvFailed, and the one of the contract creations seen that
+// succeeded is set to EvCreated.
+func (e *Explorer) receipts(net string, txs []types.Trans) {
+	nexp, c, _ := e.network(net)
+
+	type receipt struct {
+		tx  types.Trans
+		err error
+	}
+
+	got := make(map[string]receipt, len(txs)) // receipts by hash
+
+	for i := range txs {
+		r, ok := got[txs[i].Hash]
+		if !ok {
+			r.tx = txs[i]
+			r.err = c.Receipt(&r.tx)
+			got[txs[i].Hash] = r
+		}
+
+		if r.err != nil {
+			log.Printf("[%s] Cannot get receipt of transaction %s, err:%e", net, txs[i].Hash, r.err)
+
+			continue
+		}
+
+		txs[i].Status, txs[i].Gas, txs[i].Price, txs[i].Fee = r.tx.Status, r.tx.Gas, r.tx.Price, r.tx.Fee
+		txs[i].Contract = r.tx.Contract
+
+		if txs[i].Event != types.EvSeen {
+			continue
+		}
+
+		if txs[i].Status == types.TxFailed && nexp.Tracked(txs[i].Hash) {
+			txs[i].Event = types.EvFailed
+		} else if txs[i].Status == types.TxSuccess && txs[i].Kind == types.KindCreate {
+			txs[i].Event = types.EvCreated
+		}
+	}
+}
+
+// untrack stops monitoring a transaction, deleting it from DB.
+func (e *Explorer) untrack(net, hash string) {
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return
+	}
+
+	if _, ok = nexp.Untrack(hash); !ok {
+		return
+	}
+
+	if err := e.db.RemoveTx(store.Tx{Hash: hash}, net); err != nil {
+		log.Printf("[%s] Error deleting transaction %s from DB %e", net, hash, err)
+	}
+}
+
+// Reorg recovers the network explorer for blockchain named 'net' from a chain reorganization. It finds the common
+// ancestor between the blocks kept by the explorer and the canonical chain of the node and rewinds the explorer to it,
+// so the blocks of the new canonical chain are scanned next. The events that had been sent for the orphaned blocks are
+// sent again as removed events. If the reorganization is deeper than the blocks kept, the explorer is rewound to the
+// oldest block it can and a ne.ErrReorgTooDeep error is returned, so the exploring can go on but the caller knows that
+// blocks older than MaxBlocks may have changed.
+func (e *Explorer) Reorg(net string) error {
+	nexp, c, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	canonical := make(map[uint64]string) // canonical hashes by block number
+
+	ancestor, err := nexp.FindAncestor(func(n uint64) (string, error) {
+		var b map[string]interface{}
+		if err := c.GetBlock(n, false, &b); err != nil {
+			return "", fmt.Errorf("explorer: cannot get block: %w", err)
+		}
+
+		blk, err := c.DecodeBlock(b)
+		if err != nil {
+			return "", fmt.Errorf("explorer: cannot decode block: %w", err)
+		}
+
+		canonical[n] = blk.Hash
+
+		return blk.Hash, nil
+	})
+	if err != nil && !errors.Is(err, ne.ErrReorgTooDeep) {
+		return fmt.Errorf("explorer: cannot find common ancestor: %w", err)
+	}
+
+	if errors.Is(err, ne.ErrReorgTooDeep) {
+		// rewind as far as possible, clearing all hashes kept so the explorer can go on
+		ancestor = 0
+		if nexp.Block > uint64(c.MaxBlocks()) {
+			ancestor = nexp.Block - uint64(c.MaxBlocks())
+		}
+
+		err = fmt.Errorf("explorer: reorg at block %d: %w", nexp.Block, err)
+	}
+
+	log.Printf("[%s] Reorg: rewinding from block %d to common ancestor %d", net, nexp.Block, ancestor)
+
+	rem, errRew := nexp.Rewind(ancestor, canonical)
+	if errRew != nil {
+		return fmt.Errorf("explorer: cannot rewind to block %d: %w", ancestor, errRew)
+	}
+	// retract the events sent for the orphaned blocks
+	if len(rem) > 0 {
+		errSend := e.mb.SendTrans(net, rem)
+		log.Printf("[%s] Sending %d removed events:%+v err:%e\n", net, len(rem), rem, errSend)
+	}
+
+	return err
+}
+
+// ManageWalletRequests starts a go routine to receive and manage wallet requests for objects (addresses and
+// transactions) to be monitored for the blockchain named 'net'.
 func (e *Explorer) ManageWalletRequests(net string) error {
 	var mut *sync.Mutex = new(sync.Mutex)
 
@@ -190,8 +636,6 @@ func (e *Explorer) ManageWalletRequests(net string) error {
 		return fmt.Errorf("explorer: cannot get requests: %w", err)
 	}
 
-	nexp := e.nem[net]
-
 	// launch request channel reader
 	go func() {
 		log.Printf("[%s] Start listening to wallet request channel", net)
@@ -202,10 +646,28 @@ func (e *Explorer) ManageWalletRequests(net string) error {
 				if !ok {
 					log.Printf("[%s] Stop listening to wallet request channel", net)
 
-					break
+					return
 				}
 
 				log.Printf("Received request %+v", req)
+				// control commands
+				if req.Type == msg.CTRL || req.Type == msg.EXIT {
+					if err := e.control(net, req); err != nil {
+						log.Printf("[%s] Control command %s failed: %e", net, req.Obj, err)
+					}
+
+					mut.Unlock()
+
+					continue
+				}
+				// the network may have been removed
+				nexp, c, okN := e.network(net)
+				if !okN {
+					log.Printf("[%s] Network is not being explored, ignoring request", net)
+					mut.Unlock()
+
+					continue
+				}
 				// validate request
 				if req.Net != net || (req.Type != msg.ADDRESS && req.Type != msg.TX) ||
 					len(req.Obj) == 0 || (req.Act != msg.LISTEN && req.Act != msg.UNLISTEN) {
@@ -214,7 +676,19 @@ func (e *Explorer) ManageWalletRequests(net string) error {
 				}
 				// process object
 				if req.Type == msg.ADDRESS {
+					addr, err := c.Address(req.Obj)
+					if err != nil {
+						log.Printf("[%s] Request has a wrong address: %e", net, err)
+						mut.Unlock()
+
+						continue
+					}
+
+					req.Obj = addr // requests from older wallets may not have the canonical form
 					a := store.Address{Addr: req.Obj}
+					if req.Sub != nil {
+						a.Sub = *req.Sub
+					}
 
 					if req.Act == msg.LISTEN {
 						// save it to DB
@@ -222,9 +696,8 @@ func (e *Explorer) ManageWalletRequests(net string) error {
 							log.Printf("[%s] Error adding WalletReq address to DB %e", net, err)
 						}
 						// include it in NetExplorer
-						nexp.Add(req.Obj, "listen")
-						log.Printf("[%s] Added object %s to NetExplorer %v %v %v %v", net, req.Obj,
-							nexp.Block, nexp.Bh, nexp.Bhi, nexp.Map)
+						nexp.Add(req.Obj, a.Sub)
+						log.Printf("[%s] Added object %s to NetExplorer %+v", net, req.Obj, a.Sub)
 					} else {
 						// delete from NetExplorer
 						if _, ok := nexp.Del(req.Obj); !ok {
@@ -234,11 +707,37 @@ func (e *Explorer) ManageWalletRequests(net string) error {
 						if err := e.db.RemoveAddress(a, net); err != nil {
 							log.Printf("[%s] Error deleting WalletReq address from DB %e", net, err)
 						}
-						log.Printf("[%s] Removed object %s from NetExplorer %v %v %v %v", net, req.Obj,
-							nexp.Block, nexp.Bh, nexp.Bhi, nexp.Map)
+						log.Printf("[%s] Removed object %s from NetExplorer", net, req.Obj)
 					}
 				} else if req.Type == msg.TX {
-					log.Printf("Un/listen to transaction TODO!!!!")
+					tx := store.Tx{Hash: req.Obj}
+					if req.Sub != nil {
+						tx.Sub = *req.Sub
+					}
+
+					if req.Act == msg.LISTEN {
+						if req.Timeout > 0 {
+							tx.Deadline = time.Now().Unix() + req.Timeout
+						}
+						// save it to DB
+						if err := e.db.AddTx(tx, net); err != nil {
+							log.Printf("[%s] Error adding WalletReq transaction to DB %e", net, err)
+						}
+						// include it in NetExplorer
+						nexp.Track(tx)
+						log.Printf("[%s] Added transaction %s to NetExplorer, deadline:%d", net, req.Obj, tx.Deadline)
+					} else {
+						// delete from NetExplorer
+						if _, ok := nexp.Untrack(req.Obj); !ok {
+							log.Printf("[%s] Error deleting WalletReq transaction %s from NetExplorer. Not found. Ignoring...",
+								net, req.Obj)
+						}
+						// delete from DB
+						if err := e.db.RemoveTx(tx, net); err != nil {
+							log.Printf("[%s] Error deleting WalletReq transaction from DB %e", net, err)
+						}
+						log.Printf("[%s] Removed transaction %s from NetExplorer", net, req.Obj)
+					}
 				}
 
 				mut.Unlock()
@@ -246,7 +745,7 @@ func (e *Explorer) ManageWalletRequests(net string) error {
 				if !ok {
 					log.Printf("[%s] Stop listening to wallet request channel", net)
 
-					break
+					return
 				}
 
 				log.Printf("[%s] Received error %+v", net, e)
@@ -7,7 +7,10 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -69,7 +72,7 @@ func TestExploreChain(t *testing.T) {
 
 	// instantiate an explorer and setup a netExplorer with an address to monitor
 	e := New(dbType, s, mb, bc)
-	if e.nem[net], err = netexplorer.New(net, e.bc[net].MaxBlocks(), []store.ListenedAddresses{
+	if e.nem[net], err = netexplorer.New(net, e.bc[net].MaxBlocks(), nil, []store.ListenedAddresses{
 		{
 			Net: net,
 			Addr: []store.Address{
@@ -80,7 +83,7 @@ func TestExploreChain(t *testing.T) {
 				},
 			},
 		},
-	}, e.db); err != nil {
+	}, nil, e.db); err != nil {
 		t.Errorf("[%s] netexplorer.New failed:%e", net, err)
 
 		return
@@ -105,19 +108,52 @@ func TestExploreChain(t *testing.T) {
 		var ret chan string = make(chan string, 1)
 
 		e.ExploreChain(net, ret)
-		defer e.StopExplorer()
 
 		t.Logf("ExploreChain finished: %s", <-ret)
 		// stop test
 		finish <- 1
 	}()
 
+	// events expected for each block: block 2 has the token send transaction and its Transfer log
 	//nolint:lll // test data
-	ts := []types.Trans{
-		{Block: "0x29bf9b", Hash: "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", Token: "", Value: "0x16345785d8a0000"},
-		{Block: "0x29bf9c", Hash: "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", Token: "0x7762440182222620a7435195208038708d27ee41", Value: "0x12309ce54000"},
-		{Block: "0x29bf9d", Hash: "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", Token: "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", Value: "0x12309ce54000"},
-		{Block: "0x29bf9e", Hash: "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61", Token: "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", Value: "0x12309ce54000"},
+	blocks := [][]types.Trans{
+		{{Block: "0x29bf9b", BlockHash: "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", TS: 0x5a952da9, Hash: "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", Token: "", Value: "0x16345785d8a0000", Gas: "0x5208", Fee: 21000000000000, Status: types.TxSuccess}},
+		{
+			{Block: "0x29bf9c", BlockHash: "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed7", TS: 0x5a952da9, Hash: "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", Token: "", Value: "0x0", Gas: "0x5208", Fee: 21000000000000, Status: types.TxSuccess},
+			{Block: "0x29bf9c", BlockHash: "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed7", TS: 0x5a952da9, Hash: "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", Token: "0x7762440182222620a7435195208038708d27ee41", Value: "0x12309ce54000", Gas: "0x5208", Fee: 21000000000000, Status: types.TxSuccess, Kind: types.KindToken, LogIndex: "0x0"},
+		},
+		{{Block: "0x29bf9d", BlockHash: "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed8", TS: 0x5a952da9, Hash: "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", Token: "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", Value: "0x12309ce54000", Gas: "0x5208", Fee: 21000000000000, Status: types.TxSuccess, Kind: types.KindToken, LogIndex: "0x1"}},
+		{{Block: "0x29bf9e", BlockHash: "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed9", TS: 0x5a952da9, Hash: "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61", Token: "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", Value: "0x12309ce54000", Gas: "0x5208", Fee: 21000000000000, Status: types.TxFailed, Kind: types.KindToken, LogIndex: "0x1"}},
+	}
+	// with 0 confirmations, each transaction is seen and confirmed in the same block
+	var ts []types.Trans
+
+	for _, blk := range blocks {
+		for _, tx := range blk {
+			tx.Event = types.EvSeen
+			ts = append(ts, tx)
+		}
+
+		for _, tx := range blk {
+			tx.Event = types.EvConfirmed
+			ts = append(ts, tx)
+		}
+	}
+
+	reorg := len(ts) // the explorer has parsed block 4 once all these events are received
+	// after the reorg, the transaction in block 4 is removed and then block 4bis contains the same transaction
+	tx := blocks[3][0]
+	tx.Removed = "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed9"
+	tx.Replaced = "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244eda"
+
+	tx.Event = types.EvRemoved
+	ts = append(ts, tx)
+	// the events of block 4bis have its timestamp and hash
+	tx.TS, tx.BlockHash = 0x5a952dc9, tx.Replaced
+
+	for _, ev := range []string{types.EvReemitted, types.EvConfirmed} {
+		tx.Event = ev
+		ts = append(ts, tx)
 	}
 
 	// reveive events and run tests
@@ -126,7 +162,11 @@ func TestExploreChain(t *testing.T) {
 		select {
 		case eve, ok := (<-eveCh):
 			// t.Logf("[%s] Received event %d %+v", net, i+1, eve) // we just log it to console!! XXX
-			if ts[i].Block != eve.Block || ts[i].Hash != eve.Hash || ts[i].Token != eve.Token || ts[i].Value != eve.Value {
+			if ts[i].Block != eve.Block || ts[i].Hash != eve.Hash || ts[i].Token != eve.Token || ts[i].Value != eve.Value ||
+				ts[i].Event != eve.Event || ts[i].Removed != eve.Removed || ts[i].Replaced != eve.Replaced ||
+				ts[i].Status != eve.Status || ts[i].Gas != eve.Gas || ts[i].Fee != eve.Fee ||
+				ts[i].TS != eve.TS || ts[i].BlockHash != eve.BlockHash || ts[i].Kind != eve.Kind ||
+				ts[i].LogIndex != eve.LogIndex {
 				t.Errorf("Error in event %d received %v", i, eve)
 			}
 
@@ -136,6 +176,13 @@ func TestExploreChain(t *testing.T) {
 			// test whatever TODO
 			mut.Unlock()
 			i++
+
+			switch i {
+			case reorg: // the explorer has parsed block 4, so lets replace it in the mock chain
+				atomic.StoreInt32(&reorged, 1)
+			case len(ts): // all the events have been received
+				e.StopExplorer()
+			}
 		case errEv, ok := (<-eveErr):
 			t.Logf("[%s] Received error %+v", net, errEv) // we just log it to console!! XXX
 
@@ -158,10 +205,28 @@ type mockRequest struct {
 type mockResponse struct {
 	Version string           `json:"jsonrpc"`
 	ID      *json.RawMessage `json:"id"`
-	Result  interface{}      `json:"result,omitempty"`
+	Result  interface{}      `json:"result"`
 	Error   interface{}      `json:"error,omitempty"`
 }
 
+// reorged is set by TestExploreChain once the explorer has parsed block 4, then the mock chain replaces block 4 by
+// block 4bis and mines block 5 on top of it.
+var reorged int32 //nolint:gochecknoglobals // test chain state
+
+// mockBlock returns the block data served by the mock server for block number 'n' or nil if not mined yet.
+func mockBlock(n uint64) interface{} {
+	blocks := mock[:4]
+	if atomic.LoadInt32(&reorged) == 1 {
+		blocks = append(blocks[:3:3], mock[4:]...)
+	}
+
+	if n == 0 || n > uint64(len(blocks)) {
+		return nil
+	}
+
+	return blocks[n-1]
+}
+
 // define handler for mock HTTP server.
 //nolint:gochecknoglobals // test handler
 var handler = func(w http.ResponseWriter, r *http.Request) {
@@ -199,12 +264,46 @@ var handler = func(w http.ResponseWriter, r *http.Request) {
 	res.ID = req.ID
 
 	// reply with expected value
-	var i int
-	var buf []byte = []byte(*res.ID)
-	for j := 0; j < len(buf); j++ {
-		i = i*10 + int(buf[j]-0x30)
+	switch req.Method {
+	case "eth_getBlockByNumber":
+		var params []interface{}
+		if err = json.Unmarshal(*req.Params, &params); err != nil || len(params) == 0 {
+			res.Error = fmt.Errorf("Error unmarshaling params:%w", err)
+
+			return
+		}
+
+		n, _ := strconv.ParseUint(params[0].(string), 0, 64)
+		res.Result = mockBlock(n)
+	case "eth_getLogs":
+		var params []struct {
+			BlockHash string `json:"blockHash"`
+		}
+		if err = json.Unmarshal(*req.Params, &params); err != nil || len(params) == 0 {
+			res.Error = fmt.Errorf("Error unmarshaling params:%w", err)
+
+			return
+		}
+
+		res.Result = mockLogs[params[0].BlockHash]
+	case "eth_getTransactionReceipt":
+		var params []string
+		if err = json.Unmarshal(*req.Params, &params); err != nil || len(params) == 0 {
+			res.Error = fmt.Errorf("Error unmarshaling params:%w", err)
+
+			return
+		}
+		// the token transfer of block 4 is reverted, all the others succeed
+		status := "0x1"
+		if params[0] == "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61" {
+			status = "0x0"
+		}
+
+		res.Result = map[string]interface{}{"transactionHash": params[0], "status": status, "gasUsed": "0x5208",
+			"effectiveGasPrice": "0x3b9aca00"}
+	default:
+		res.Result = nil
 	}
-	res.Result = mock[i]
 }
 
 // TestManageWalletRequests is a component test!!
@@ -238,7 +337,7 @@ func TestManageWalletRequest(t *testing.T) {
 
 	// instantiate an explorer and setup netExplorer
 	e := New(dbType, s, mb, bc)
-	if e.nem[net], err = netexplorer.New(net, e.bc[net].MaxBlocks(), nil, e.db); err != nil {
+	if e.nem[net], err = netexplorer.New(net, e.bc[net].MaxBlocks(), nil, nil, nil, e.db); err != nil {
 		t.Errorf("[%s] netexplorer.New failed:%e", net, err)
 
 		return
@@ -251,12 +350,19 @@ func TestManageWalletRequest(t *testing.T) {
 
 	// test steps: a WalletReq is sent. Then check the map's len, and if an object is specified, we check the
 	// existence of that object in the map
+	const (
+		addr1 = "0xcba75f167b03e34b8a572c50273c082401b073ed"
+		addr2 = "0x357dd3856d856197c1a000bbab4abcb97dfc92c4"
+	)
+
+	//nolint:lll // one test step per line
 	ts := []interface{}{
-		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "addr1", Act: msg.UNLISTEN}, 0, ""},
-		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "addr1", Act: msg.LISTEN}, 1, "addr1", true},
-		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "addr2", Act: msg.LISTEN}, 2, "addr2", true},
-		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "addr1", Act: msg.LISTEN}, 2, "addr1", true},
-		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "addr1", Act: msg.UNLISTEN}, 1, "addr1", false},
+		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "addr3", Act: msg.LISTEN}, 0, "addr3", false},
+		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: addr1, Act: msg.UNLISTEN}, 0, ""},
+		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: addr1, Act: msg.LISTEN}, 1, addr1, true},
+		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: addr2, Act: msg.LISTEN}, 2, addr2, true},
+		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: "0xCBA75F167B03E34B8A572C50273C082401B073ED", Act: msg.LISTEN}, 2, addr1, true},
+		[]interface{}{msg.WalletReq{Net: net, Type: msg.ADDRESS, Obj: addr1, Act: msg.UNLISTEN}, 1, addr1, false},
 	}
 
 	// run test
@@ -268,13 +374,13 @@ func TestManageWalletRequest(t *testing.T) {
 
 		time.Sleep(50 * time.Millisecond) // let the go routine finish managing the request
 		// check result
-		if len(e.nem[net].Map) != step.([]interface{})[1].(int) {
-			t.Errorf("Map len (%d) does not match in step:%+v", len(e.nem[net].Map), step)
+		if addrs, _ := e.nem[net].Monitored(); addrs != step.([]interface{})[1].(int) {
+			t.Errorf("Monitored addresses (%d) does not match in step:%+v", addrs, step)
 		}
 
 		if step.([]interface{})[2].(string) != "" {
-			if _, ok := e.nem[net].Map[step.([]interface{})[2].(string)]; ok != step.([]interface{})[3].(bool) {
-				t.Errorf("Object in Map (%v) does not match in step:%+v", e.nem[net].Map, step)
+			if _, ok := e.nem[net].Sub(step.([]interface{})[2].(string)); ok != step.([]interface{})[3].(bool) {
+				t.Errorf("Monitored address does not match in step:%+v", step)
 			}
 		}
 	}
@@ -321,6 +427,63 @@ func TestExplorer(t *testing.T) {
 	t.Logf("ret:%s", <-ret)
 }
 
+// receiptChain is a mock chain counting the receipts got, only implementing the methods used by receipts.
+type receiptChain struct {
+	adminChain
+	got int
+}
+
+func (c *receiptChain) Receipt(tx *types.Trans) error {
+	c.got++
+	tx.Status, tx.Gas, tx.Fee = types.TxSuccess, "0x5208", 21000
+
+	return nil
+}
+
+// TestReceipts tests the receipt of a transaction is got once for all its events in the block.
+func TestReceipts(t *testing.T) {
+	c := &receiptChain{adminChain: adminChain{fetchChain{head: 120}}}
+	e := New("", &adminDB{}, nil, map[string]block.Chain{net: c})
+
+	var err error
+	if e.nem[net], err = netexplorer.New(net, 12, nil, nil, nil, e.db); err != nil {
+		t.Fatalf("netexplorer.New err:%e", err)
+	}
+
+	txs := []types.Trans{{Hash: "0x01"}, {Hash: "0x01", Kind: types.KindToken}, {Hash: "0x02"}}
+	e.receipts(net, txs)
+
+	if c.got != 2 || txs[1].Status != types.TxSuccess || txs[1].Fee != 21000 || txs[2].Gas != "0x5208" {
+		t.Errorf("receipts got:%d txs:%+v", c.got, txs)
+	}
+}
+
+// mockLog returns a Transfer log of 'value' tokens from 'from' to 'to' for the mock server.
+func mockLog(block, hash, index, token, from, to, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"address":         token,
+		"blockNumber":     block,
+		"transactionHash": hash,
+		"logIndex":        index,
+		"removed":         false,
+		"topics": []string{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			"0x000000000000000000000000" + from[2:], "0x000000000000000000000000" + to[2:]},
+		"data": "0x" + strings.Repeat("0", 66-len(value)) + value[2:],
+	}
+}
+
+// mockLogs contains the Transfer logs served by the mock server for each block hash in TestExploreChain.
+//nolint:lll,gochecknoglobals // test data
+var mockLogs = map[string][]map[string]interface{}{
+	// block 2: a token send transaction
+	"0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed7": {mockLog("0x29bf9c", "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "0x0", "0x7762440182222620a7435195208038708d27ee41", "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", "0x1ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83", "0x12309ce54000")},
+	// block 3: a token transferFrom send transaction
+	"0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed8": {mockLog("0x29bf9d", "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "0x1", "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "0x12309ce54000")},
+	// block 4 and 4bis: a token transferFrom receive transaction
+	"0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed9": {mockLog("0x29bf9e", "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61", "0x1", "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", "0x12309ce54000")},
+	"0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244eda": {mockLog("0x29bf9e", "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61", "0x1", "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", "0x12309ce54000")},
+}
+
 // mock contains the data used by the mock server in TestExploreChain.
 //nolint:lll,gochecknoglobals // test data
 var mock []interface{} = []interface{}{
@@ -332,6 +495,8 @@ var mock []interface{} = []interface{}{
 	map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed8", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9d", "parentHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed7", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952da9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []map[string]interface{}{{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9d", "from": "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "gas": "0x47b760", "gasPrice": "0x174876e800", "hash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "input": "0x4bdb8ab50804004410241002040000c60890801000000000000000000000000000000000", "nonce": "0x46", "r": "0xdd38a14e41b886d156a1073cc7ae914f4ee70d282925652b366bf953311d5862", "s": "0x4ecacbcef27ca7ebb7f8f628036a555f934a124063869fa8ba256ef7731218cf", "to": "0x7762440182222620a7435195208038708d27ee41", "transactionIndex": "0x0", "v": "0x1c", "value": "0x0"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9d", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x23b872dd000000000000000000000000357dd3856d856197c1a000bbAb4aBCB97Dfc92c4000000000000000000000000c4581843a8dacd100c7d435bb00b2a20d038e31d000000000000000000000000000000000000000000000000000012309ce54000", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}},
 	// block 4: a token transferTo receive transaction
 	map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed9", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9e", "parentHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed8", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952da9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []map[string]interface{}{{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9e", "from": "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "gas": "0x47b760", "gasPrice": "0x174876e800", "hash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "input": "0x4bdb8ab50804004410241002040000c60890801000000000000000000000000000000000", "nonce": "0x46", "r": "0xdd38a14e41b886d156a1073cc7ae914f4ee70d282925652b366bf953311d5862", "s": "0x4ecacbcef27ca7ebb7f8f628036a555f934a124063869fa8ba256ef7731218cf", "to": "0x7762440182222620a7435195208038708d27ee41", "transactionIndex": "0x0", "v": "0x1c", "value": "0x0"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9e", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61", "input": "0x23b872dd000000000000000000000000c4581843a8dacd100c7d435bb00b2a20d038e31d000000000000000000000000357dd3856d856197c1a000bbAb4aBCB97Dfc92c4000000000000000000000000000000000000000000000000000012309ce54000", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9e", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}},
-	// block 5 - it is not chained
-	map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed9", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9f", "parentHash": "0x25e2e6cfc2f49ef320c652d91a7bea99a2d115d29ea832631e5f11911a463158", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952da9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []map[string]interface{}{{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "gas": "0x47b760", "gasPrice": "0x174876e800", "hash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "input": "0x4bdb8ab50804004410241002040000c60890801000000000000000000000000000000000", "nonce": "0x46", "r": "0xdd38a14e41b886d156a1073cc7ae914f4ee70d282925652b366bf953311d5862", "s": "0x4ecacbcef27ca7ebb7f8f628036a555f934a124063869fa8ba256ef7731218cf", "to": "0x7762440182222620a7435195208038708d27ee41", "transactionIndex": "0x0", "v": "0x1c", "value": "0x0"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}},
+	// block 4bis - replaces block 4 after the reorg
+	map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244eda", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9e", "parentHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed8", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952dc9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []map[string]interface{}{{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9e", "from": "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "gas": "0x47b760", "gasPrice": "0x174876e800", "hash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "input": "0x4bdb8ab50804004410241002040000c60890801000000000000000000000000000000000", "nonce": "0x46", "r": "0xdd38a14e41b886d156a1073cc7ae914f4ee70d282925652b366bf953311d5862", "s": "0x4ecacbcef27ca7ebb7f8f628036a555f934a124063869fa8ba256ef7731218cf", "to": "0x7762440182222620a7435195208038708d27ee41", "transactionIndex": "0x0", "v": "0x1c", "value": "0x0"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9e", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf61", "input": "0x23b872dd000000000000000000000000c4581843a8dacd100c7d435bb00b2a20d038e31d000000000000000000000000357dd3856d856197c1a000bbAb4aBCB97Dfc92c4000000000000000000000000000000000000000000000000000012309ce54000", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}, {"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9e", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}},
+	// block 5 - mined on top of block 4bis
+	map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244edb", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9f", "parentHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244eda", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952da9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []interface{}{}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}},
 }
@@ -0,0 +1,123 @@
+package explorer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tarancss/adp/lib/block"
+)
+
+// errFetcherClosed is returned for the blocks requested after the fetcher is closed.
+var errFetcherClosed = errors.New("explorer: block fetcher closed")
+
+// blockResult contains the data of a block requested to the node or the error got when requesting it.
+type blockResult struct {
+	n   uint64                 // block number
+	b   map[string]interface{} // block data
+	err error
+}
+
+// fetcher requests a window of consecutive blocks concurrently so an explorer that is behind the chain head can catch
+// up, but returns them strictly in order. The requests to the node are limited to the chain's rate. When a block is
+// not available (the chain head has been reached) the window shrinks to 1 block, and grows again up to the chain's
+// window while the blocks requested are available.
+type fetcher struct {
+	c       block.Chain
+	next    uint64             // next block number to be requested
+	size    int                // current window size
+	pending []chan blockResult // blocks requested, in order
+	tokens  chan struct{}      // rate limiter, a request can be made for each token
+	done    chan struct{}      // closed when the fetcher is closed
+}
+
+// newFetcher returns a fetcher for chain 'c' that starts requesting block 'next'. The fetcher must be closed when it
+// is not used any longer.
+func newFetcher(c block.Chain, next uint64) *fetcher {
+	f := &fetcher{
+		c:      c,
+		next:   next,
+		size:   1,
+		tokens: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	rate := c.Rate()
+	if rate < 1 {
+		rate = 1
+	}
+
+	go func() {
+		t := time.NewTicker(time.Second / time.Duration(rate))
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				select {
+				case f.tokens <- struct{}{}:
+				case <-f.done:
+					return
+				}
+			case <-f.done:
+				return
+			}
+		}
+	}()
+
+	return f
+}
+
+// get returns the next block in order. If there was an error getting it, the blocks requested after it are discarded
+// and it will be requested again in the next call.
+func (f *fetcher) get() blockResult {
+	for len(f.pending) < f.size {
+		ch := make(chan blockResult, 1)
+		go f.request(f.next, ch)
+
+		f.pending = append(f.pending, ch)
+		f.next++
+	}
+
+	r := <-f.pending[0]
+	f.pending = f.pending[1:]
+
+	if r.err != nil {
+		f.reset(r.n)
+		f.size = 1
+
+		return r
+	}
+
+	if f.size < f.c.Window() {
+		f.size++
+	}
+
+	return r
+}
+
+// reset discards the blocks requested so the next block returned is block 'next'.
+func (f *fetcher) reset(next uint64) {
+	f.pending = nil
+	f.next = next
+}
+
+// close stops the rate limiter. Blocks being requested are returned with errFetcherClosed.
+func (f *fetcher) close() {
+	close(f.done)
+}
+
+// request waits for the rate limiter and requests block 'n' to the node, sending the result to 'ch'.
+func (f *fetcher) request(n uint64, ch chan<- blockResult) {
+	select {
+	case <-f.tokens:
+	case <-f.done:
+		ch <- blockResult{n: n, err: errFetcherClosed}
+
+		return
+	}
+
+	var b map[string]interface{}
+
+	err := f.c.GetBlock(n, true, &b)
+	ch <- blockResult{n: n, b: b, err: err}
+}
@@ -0,0 +1,63 @@
+package explorer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/block/types"
+)
+
+// fetchChain is a mock chain with 'head' blocks mined, only implementing the methods used by the fetcher.
+type fetchChain struct {
+	block.Chain
+	head uint64
+}
+
+func (c *fetchChain) Rate() int   { return 1000 }
+func (c *fetchChain) Window() int { return 4 }
+
+func (c *fetchChain) GetBlock(n uint64, full bool, response interface{}) error {
+	if n > atomic.LoadUint64(&c.head) {
+		return types.ErrNoBlock
+	}
+
+	*response.(*map[string]interface{}) = map[string]interface{}{"number": n}
+
+	return nil
+}
+
+// TestFetcher tests the fetcher returns the blocks in order, and resets at the chain head or when requested.
+func TestFetcher(t *testing.T) {
+	c := &fetchChain{head: 10}
+
+	f := newFetcher(c, 1)
+	defer f.close()
+
+	for n := uint64(1); n <= 10; n++ {
+		if r := f.get(); r.err != nil || r.n != n || r.b["number"] != n {
+			t.Errorf("get block %d returned:%+v", n, r)
+		}
+	}
+
+	if f.size != 4 {
+		t.Errorf("window size should have grown to 4 but is %d", f.size)
+	}
+	// chain head reached
+	if r := f.get(); !errors.Is(r.err, types.ErrNoBlock) || r.n != 11 || f.size != 1 || len(f.pending) != 0 {
+		t.Errorf("get block 11 returned:%+v size:%d pending:%d", r, f.size, len(f.pending))
+	}
+
+	atomic.StoreUint64(&c.head, 11)
+
+	if r := f.get(); r.err != nil || r.n != 11 {
+		t.Errorf("get block 11 returned:%+v", r)
+	}
+	// rewind
+	f.reset(5)
+
+	if r := f.get(); r.err != nil || r.n != 5 {
+		t.Errorf("get block 5 after reset returned:%+v", r)
+	}
+}
@@ -0,0 +1,240 @@
+package explorer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	ne "github.com/tarancss/adp/explorer/netexplorer"
+	"github.com/tarancss/adp/lib/store"
+)
+
+// ErrStandby is returned when restarting a network whose lease is held by another explorer.
+var ErrStandby = errors.New("explorer: network is explored by another explorer")
+
+// Lease enables the election of a leader among multiple explorers (replicas) for each network, so only the explorer
+// holding the lease of a network explores it and consumes its wallet requests, while the others wait on standby to take
+// over when the lease expires. Leases are acquired and renewed in DB (see store.DB.AcquireLease) every ttl/3 by the
+// explorer identified by 'owner', which stops exploring a network if it cannot renew its lease for 'ttl'. The fencing
+// token of the lease is saved with the network explorer, so a former leader cannot overwrite it. Networks added or
+// removed at runtime by another explorer are also added or removed. Must be called before Explore.
+func (e *Explorer) Lease(owner string, ttl time.Duration) {
+	e.owner, e.ttl, e.quit = owner, ttl, make(chan struct{})
+}
+
+// lead acquires or renews the leases of the networks every ttl/3 until StopExplorer is called, when the leases held are
+// released.
+func (e *Explorer) lead() {
+	defer e.wg.Done()
+
+	t := time.NewTicker(e.ttl / 3) //nolint:gomnd // renew 3 times per lease
+	defer t.Stop()
+
+	for {
+		e.syncNetworks()
+
+		for _, net := range e.networks() {
+			e.campaign(net)
+		}
+
+		select {
+		case <-t.C:
+		case <-e.quit:
+			for _, net := range e.networks() {
+				e.resign(net)
+			}
+
+			return
+		}
+	}
+}
+
+// networks returns the names of the networks explored.
+func (e *Explorer) networks() []string {
+	e.nl.RLock()
+	defer e.nl.RUnlock()
+
+	nets := make([]string, 0, len(e.nem))
+	for net := range e.nem {
+		nets = append(nets, net)
+	}
+
+	return nets
+}
+
+// campaign acquires or renews the lease of blockchain named 'net', taking over its exploration if it was on standby, or
+// stepping down if the lease is held by another explorer or could not be renewed for ttl.
+func (e *Explorer) campaign(net string) {
+	e.rl.Lock()
+	defer e.rl.Unlock()
+
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return
+	}
+
+	token, err := e.db.AcquireLease(net, e.owner, e.ttl)
+
+	e.l.Lock()
+	s := e.state(net)
+	leader, renewed := s.leader, s.renewed
+
+	if err == nil && leader {
+		s.renewed = time.Now()
+	}
+	e.l.Unlock()
+
+	switch {
+	case err == nil && !leader:
+		if err = e.takeOver(net, nexp, token); err != nil {
+			log.Printf("[%s] Cannot take over the exploration, err:%e", net, err)
+			e.fail(net, err)
+		}
+	case leader && (errors.Is(err, store.ErrLeaseHeld) || time.Since(renewed) > e.ttl):
+		log.Printf("[%s] Lease lost, err:%e", net, err)
+		e.stepDown(net, nexp)
+	case err != nil && !errors.Is(err, store.ErrLeaseHeld):
+		log.Printf("[%s] Cannot acquire lease, err:%e", net, err)
+		e.fail(net, err)
+	}
+}
+
+// takeOver starts exploring blockchain named 'net' from the network explorer saved in DB by the former leader, whose
+// monitored addresses and transactions are reloaded. Must be called with rl held.
+func (e *Explorer) takeOver(net string, nexp *ne.NetExplorer, token int64) error {
+	s, err := e.db.LoadExplorer(net)
+	if err != nil && !errors.Is(err, store.ErrDataNotFound) {
+		return fmt.Errorf("explorer: cannot load net explorer: %w", err)
+	}
+
+	addrs, err := e.db.GetAddresses([]string{net})
+	if err != nil {
+		return fmt.Errorf("explorer: cannot load listened addresses: %w", err)
+	}
+
+	txs, err := e.db.GetTxs([]string{net})
+	if err != nil {
+		return fmt.Errorf("explorer: cannot load listened transactions: %w", err)
+	}
+
+	if s.Bh != nil {
+		nexp.FromStore(s)
+	}
+
+	nexp.Reload(addrs, txs)
+	nexp.SetFence(token)
+	// save it with the new token right away, so the former leader cannot overwrite it
+	if err = e.db.SaveExplorer(net, nexp.ToStore()); err != nil {
+		return fmt.Errorf("explorer: cannot save net explorer: %w", err)
+	}
+
+	e.nl.Lock()
+	consuming := e.req[net]
+	e.req[net] = true
+	e.nl.Unlock()
+
+	if !consuming {
+		if err = e.ManageWalletRequests(net); err != nil {
+			e.nl.Lock()
+			delete(e.req, net)
+			e.nl.Unlock()
+
+			return fmt.Errorf("explorer: cannot consume wallet requests from broker: %w", err)
+		}
+	}
+
+	e.l.Lock()
+	st := e.state(net)
+	st.leader, st.renewed = true, time.Now()
+	e.l.Unlock()
+
+	log.Printf("[%s] Lease acquired with token %d, exploring at block %d", net, token, nexp.Cursor())
+
+	nexp.Start()
+	e.ExploreChain(net, e.ret)
+
+	return nil
+}
+
+// stepDown stops exploring blockchain named 'net' and consuming its wallet requests. Must be called with rl held.
+func (e *Explorer) stepDown(net string, nexp *ne.NetExplorer) {
+	e.l.Lock()
+	e.state(net).leader = false
+	e.l.Unlock()
+
+	if err := e.halt(net, nexp); err != nil {
+		log.Printf("[%s] Cannot stop exploring, err:%e", net, err)
+	}
+
+	e.nl.Lock()
+	consuming := e.req[net]
+	delete(e.req, net)
+	e.nl.Unlock()
+
+	if consuming {
+		if err := e.mb.StopReqs(net); err != nil {
+			log.Printf("[%s] Cannot stop consuming wallet requests, err:%e", net, err)
+		}
+	}
+}
+
+// resign steps down from the exploration of blockchain named 'net' if it is the leader, releasing its lease so another
+// explorer can take over right away.
+func (e *Explorer) resign(net string) {
+	e.rl.Lock()
+	defer e.rl.Unlock()
+
+	nexp, _, ok := e.network(net)
+	if !ok || !e.leading(net) {
+		return
+	}
+
+	e.stepDown(net, nexp)
+
+	if err := e.db.ReleaseLease(net, e.owner); err != nil {
+		log.Printf("[%s] Cannot release lease, err:%e", net, err)
+	}
+}
+
+// leading checks if the explorer can explore blockchain named 'net': leases are not used or it holds a lease renewed
+// within ttl.
+func (e *Explorer) leading(net string) bool {
+	if e.ttl == 0 {
+		return true
+	}
+
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	s := e.state(net)
+
+	return s.leader && time.Since(s.renewed) <= e.ttl
+}
+
+// syncNetworks adds or removes the networks added or removed at runtime by other explorers.
+func (e *Explorer) syncNetworks() {
+	nets, err := e.db.GetNetworks()
+	if err != nil {
+		log.Printf("Cannot get networks, err:%e", err)
+
+		return
+	}
+
+	for _, n := range nets {
+		_, _, ok := e.network(n.Conf.Name)
+
+		switch {
+		case n.Removed && ok:
+			err = e.removeNetwork(n.Conf.Name, false)
+		case !n.Removed && !ok:
+			err = e.addNetwork(n.Conf, false)
+		default:
+			continue
+		}
+
+		if err != nil {
+			log.Printf("[%s] Cannot sync network, err:%e", n.Conf.Name, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package explorer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ne "github.com/tarancss/adp/explorer/netexplorer"
+	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/msg"
+	"github.com/tarancss/adp/lib/store"
+)
+
+// leaseDB is a mock database with a lease held by 'owner', without monitored objects.
+type leaseDB struct {
+	adminDB
+	owner  string
+	token  int64
+	saved  int64              // token of the last explorer saved
+	stored *store.NetExplorer // explorer saved by the former leader, if any
+}
+
+func (d *leaseDB) LoadExplorer(net string) (store.NetExplorer, error) {
+	if d.stored == nil {
+		return store.NetExplorer{}, store.ErrDataNotFound
+	}
+
+	return *d.stored, nil
+}
+
+func (d *leaseDB) AcquireLease(net, owner string, ttl time.Duration) (int64, error) {
+	if d.owner != "" && d.owner != owner {
+		return 0, store.ErrLeaseHeld
+	}
+
+	if d.owner != owner {
+		d.owner = owner
+		d.token++
+	}
+
+	return d.token, nil
+}
+
+func (d *leaseDB) ReleaseLease(net, owner string) error {
+	if d.owner == owner {
+		d.owner = ""
+	}
+
+	return nil
+}
+
+func (d *leaseDB) SaveExplorer(net string, n store.NetExplorer) error {
+	if n.Token < d.token {
+		return store.ErrFenced
+	}
+
+	d.saved = n.Token
+
+	return nil
+}
+
+func (d *leaseDB) GetAddresses(net []string) ([]store.ListenedAddresses, error) { return nil, nil }
+func (d *leaseDB) GetTxs(net []string) ([]store.ListenedTxs, error)             { return nil, nil }
+
+// leaseMB is a mock message broker without wallet requests.
+type leaseMB struct {
+	msg.MsgBroker
+	reqs map[string]chan msg.WalletReq
+}
+
+func (m *leaseMB) GetReqs(net string, mut *sync.Mutex) (<-chan msg.WalletReq, <-chan error, error) {
+	m.reqs[net] = make(chan msg.WalletReq)
+
+	return m.reqs[net], make(chan error), nil
+}
+
+func (m *leaseMB) StopReqs(net string) error {
+	close(m.reqs[net])
+	delete(m.reqs, net)
+
+	return nil
+}
+
+// TestLease tests a network is explored only while its lease is held, and the explorer is saved with its token.
+func TestLease(t *testing.T) {
+	c := &adminChain{fetchChain{head: 120}}
+	db := &leaseDB{owner: "other", token: 1}
+	mb := &leaseMB{reqs: make(map[string]chan msg.WalletReq)}
+	e := New("", db, mb, map[string]block.Chain{"ropsten": c})
+	e.Lease("me", time.Minute)
+
+	var err error
+	if e.nem["ropsten"], err = ne.New("ropsten", 12, func() (uint64, error) { return 101, nil }, nil, nil,
+		e.db); err != nil {
+		t.Fatalf("netexplorer.New err:%e", err)
+	}
+
+	check := func(name, lease string, running bool) {
+		st, err := e.Status("ropsten")
+		if err != nil || st.Lease != lease || st.Running != running {
+			t.Errorf("%s: status:%+v err:%e", name, st, err)
+		}
+
+		if _, ok := mb.reqs["ropsten"]; ok != running {
+			t.Errorf("%s: consuming wallet requests:%t", name, ok)
+		}
+	}
+
+	// the lease is held by another explorer
+	e.campaign("ropsten")
+	check("standby", "standby", false)
+
+	if err = e.Restart("ropsten"); !errors.Is(err, ErrStandby) {
+		t.Errorf("restart on standby err:%e", err)
+	}
+
+	// the lease expires and is taken over, fencing the explorer of the former leader
+	db.owner = ""
+	e.campaign("ropsten")
+	check("leader", "leader", true)
+
+	if db.saved != 2 || e.nem["ropsten"].Fence() != 2 {
+		t.Errorf("takeover saved token %d, fence %d", db.saved, e.nem["ropsten"].Fence())
+	}
+
+	e.campaign("ropsten")
+	check("renew", "leader", true)
+
+	// the lease is lost
+	db.owner, db.token = "other", 3
+	e.campaign("ropsten")
+	check("stepDown", "standby", false)
+
+	// the lease is acquired again and released when the explorer stops
+	db.owner = ""
+	e.campaign("ropsten")
+	check("leaderAgain", "leader", true)
+
+	e.resign("ropsten")
+	check("resign", "standby", false)
+
+	if db.owner != "" {
+		t.Errorf("lease not released, owner:%s", db.owner)
+	}
+}
+
+// TestTakeOverRace tests the explorer saved by the former leader is loaded on a takeover while its status is read.
+func TestTakeOverRace(t *testing.T) {
+	c := &adminChain{fetchChain{head: 160}}
+	db := &leaseDB{stored: &store.NetExplorer{Block: 150, Bh: make([]string, 12), Bhi: 3}}
+	mb := &leaseMB{reqs: make(map[string]chan msg.WalletReq)}
+	e := New("", db, mb, map[string]block.Chain{"ropsten": c})
+	e.Lease("me", time.Minute)
+
+	nexp, err := ne.New("ropsten", 12, func() (uint64, error) { return 101, nil }, nil, nil, e.db)
+	if err != nil {
+		t.Fatalf("netexplorer.New err:%e", err)
+	}
+
+	e.nem["ropsten"] = nexp
+
+	done, read, reading := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(read)
+
+		_ = nexp.Cursor()
+		close(reading)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = nexp.Cursor()
+				_, _ = e.Status("ropsten")
+			}
+		}
+	}()
+
+	<-reading
+	e.campaign("ropsten")
+	close(done)
+	<-read
+
+	if b := nexp.Cursor(); b != 150 {
+		t.Errorf("takeover loaded block %d", b)
+	}
+
+	e.resign("ropsten")
+}
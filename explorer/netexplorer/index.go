@@ -0,0 +1,150 @@
+package netexplorer
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tarancss/adp/lib/block/types"
+)
+
+// shards is the number of shards of an index. Writes copy the shard they modify, so with millions of addresses each
+// shard has to be kept small.
+const shards = 1024
+
+// index keeps the subscriptions of the monitored addresses in shards that are copied on write, so lookups do not take
+// any lock and are not blocked by additions or deletions while scanning a block.
+type index struct {
+	n      int64      // number of subscriptions, accessed atomically (first field to be 64-bit aligned)
+	l      sync.Mutex // serializes writers
+	shards [shards]atomic.Value
+}
+
+// newIndex returns an index loaded with the subscriptions in 'subs' by address.
+func newIndex(subs map[string]types.Sub) *index {
+	var m [shards]map[string]types.Sub
+
+	for i := range m {
+		m[i] = make(map[string]types.Sub)
+	}
+
+	for addr, s := range subs {
+		m[shard(addr)][addr] = s
+	}
+
+	x := &index{n: int64(len(subs))}
+	for i := range m {
+		x.shards[i].Store(m[i])
+	}
+
+	return x
+}
+
+// shard returns the shard of an address.
+func shard(addr string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(addr))
+
+	return h.Sum32() % shards
+}
+
+// get returns the subscription of an address, 'ok' is false if the address is not monitored.
+func (x *index) get(addr string) (s types.Sub, ok bool) {
+	if x == nil {
+		return
+	}
+
+	s, ok = x.shards[shard(addr)].Load().(map[string]types.Sub)[addr]
+
+	return
+}
+
+// set adds or replaces the subscription of an address.
+func (x *index) set(addr string, s types.Sub) {
+	x.l.Lock()
+	defer x.l.Unlock()
+
+	i := shard(addr)
+	old := x.shards[i].Load().(map[string]types.Sub)
+
+	m := make(map[string]types.Sub, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+
+	if _, ok := old[addr]; !ok {
+		atomic.AddInt64(&x.n, 1)
+	}
+
+	m[addr] = s
+	x.shards[i].Store(m)
+}
+
+// del deletes the subscription of an address returning it, 'ok' is false if the address was not monitored.
+func (x *index) del(addr string) (s types.Sub, ok bool) {
+	x.l.Lock()
+	defer x.l.Unlock()
+
+	i := shard(addr)
+	old := x.shards[i].Load().(map[string]types.Sub)
+
+	if s, ok = old[addr]; !ok {
+		return
+	}
+
+	m := make(map[string]types.Sub, len(old))
+	for k, v := range old {
+		if k != addr {
+			m[k] = v
+		}
+	}
+
+	atomic.AddInt64(&x.n, -1)
+	x.shards[i].Store(m)
+
+	return
+}
+
+// load replaces all the subscriptions with the ones in 'subs' by address.
+func (x *index) load(subs map[string]types.Sub) {
+	y := newIndex(subs)
+
+	x.l.Lock()
+	defer x.l.Unlock()
+
+	for i := range x.shards {
+		x.shards[i].Store(y.shards[i].Load())
+	}
+
+	atomic.StoreInt64(&x.n, y.n)
+}
+
+// len returns the number of subscriptions.
+func (x *index) len() int {
+	if x == nil {
+		return 0
+	}
+
+	return int(atomic.LoadInt64(&x.n))
+}
+
+// expiry is the expiration time of the subscription of an address.
+type expiry struct {
+	addr    string
+	expires int64
+}
+
+// expiries is a min-heap of expiration times (see container/heap), so only the subscriptions that expire are visited.
+type expiries []expiry
+
+func (e expiries) Len() int            { return len(e) }
+func (e expiries) Less(i, j int) bool  { return e[i].expires < e[j].expires }
+func (e expiries) Swap(i, j int)       { e[i], e[j] = e[j], e[i] }
+func (e *expiries) Push(x interface{}) { *e = append(*e, x.(expiry)) }
+func (e *expiries) Pop() interface{} {
+	old := *e
+	x := old[len(old)-1]
+	*e = old[:len(old)-1]
+
+	return x
+}
@@ -3,37 +3,61 @@
 package netexplorer
 
 import (
+	"container/heap"
 	"errors"
 	"fmt"
 	"log"
+	"math/big"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/tarancss/adp/lib/block/types"
 	"github.com/tarancss/adp/lib/store"
 )
 
-// Status possible values, control whether a NextExplorer is working or is/has to stop.
+// Status possible values, control whether a NextExplorer is working, is/has to stop or is paused.
 const (
-	WORK int = 0
-	STOP int = 1
+	WORK  int = 0
+	STOP  int = 1
+	PAUSE int = 2
 )
 
 // NetExplorer contains the fields and data structures required to manage the exploring of a network or blockchain.
 type NetExplorer struct {
-	l      sync.Mutex // l is a mutex to ensure concurrent updating of addresses in the map
-	status int        `json:"status" bson:"status"` //nolint:govet // status is accessed via methods
-	Block  uint64     `json:"block" bson:"block"`   // last block parsed
+	l      sync.Mutex // l is a mutex to ensure concurrent updating of the fields
+	status int        // status is accessed via methods
+	seek   *uint64    // block the explorer has to be moved to (see Seek)
+	rescan *rescan    // blocks being explored again (see Rescan)
+	token  int64      // fencing token of the lease of the explorer, saved with it (see store.NetExplorer)
+	Block  uint64     `json:"block" bson:"block"` // last block parsed
 
-	Bh  []string `json:"bh" bson:"bh"`   // contains the last blocks hashes (from Block-1 to Block-maxBlocks)
+	Bh  []string `json:"bh" bson:"bh"`   // contains the last blocks hashes (from Block to Block-maxBlocks+1)
 	Bhi int      `json:"bhi" bson:"bhi"` // index to last block's hash in Bh
 
-	Map map[string]interface{} `json:"map" bson:"map"` // Map of addresses/transactions to the required information
+	Ev   [][]types.Trans        `json:"ev" bson:"ev"`     // events sent for the blocks kept in Bh (same index)
+	Conf uint64                 `json:"conf" bson:"conf"` // last block whose events have been confirmed
+	Rem  map[string]types.Trans `json:"rem" bson:"rem"`   // removed events by reorgs by key (see key)
+
+	subs *index              // monitored addresses and their subscription records, they are saved to DB apart
+	exp  expiries            // expiration times of the subscriptions that expire
+	Txs  map[string]store.Tx `json:"-" bson:"-"` // monitored transactions by hash, they are saved to DB apart
+}
+
+// rescan is a range of blocks being explored again, after which the explorer is moved back to block 'back'.
+type rescan struct {
+	to, back uint64
 }
 
+// ErrReorgTooDeep is returned when the common ancestor of a chain reorganization is older than the blocks kept in Bh.
+var ErrReorgTooDeep = errors.New("chain reorganization is deeper than the blocks kept")
+
 // New tries to load from DB a previously saved status of the net explorer or creates a new one with default values
-// (start monitoring at block 1) if not present in DB. A slice of length=1 (only for one network) of addresses to
-// monitor can be passed in 'l' and returns a NetExplorer object.
-func New(net string, max int, l []store.ListenedAddresses, db store.DB) (*NetExplorer, error) {
+// if not present in DB. A new net explorer starts monitoring at the block returned by 'start', or at block 1 if start
+// is nil. A slice of length=1 (only for one network) of addresses to monitor can be passed in 'l', and likewise the
+// transactions to monitor can be passed in 't'. Returns a NetExplorer object.
+func New(net string, max int, start func() (uint64, error), l []store.ListenedAddresses, t []store.ListenedTxs,
+	db store.DB) (*NetExplorer, error) {
 	var ne NetExplorer
 
 	var s store.NetExplorer
@@ -42,10 +66,24 @@ func New(net string, max int, l []store.ListenedAddresses, db store.DB) (*NetExp
 
 	if s, err = db.LoadExplorer(net); err != nil {
 		if errors.Is(err, store.ErrDataNotFound) {
-			// if "explorer" was not present in DB, then we just create from block 0
+			// if "explorer" was not present in DB, then we just create from the start block
 			ne.Block = 0
+			if start != nil {
+				var b uint64
+				if b, err = start(); err != nil {
+					return nil, fmt.Errorf("cannot get start block: %w", err)
+				}
+
+				if b > 0 {
+					ne.Block = b - 1 // last block parsed
+				}
+			}
+
+			ne.Conf = ne.Block
 			ne.Bhi = 0
 			ne.Bh = make([]string, max)
+			ne.Ev = make([][]types.Trans, max)
+			ne.Rem = make(map[string]types.Trans)
 			ne.status = WORK
 		} else {
 			return nil, fmt.Errorf("cannot load net explorer from DB: %w", err)
@@ -54,39 +92,148 @@ func New(net string, max int, l []store.ListenedAddresses, db store.DB) (*NetExp
 		ne.FromStore(s)
 	}
 
-	ne.Map = make(map[string]interface{})
+	var subs map[string]types.Sub
+
+	subs, ne.exp = listened(l)
+	ne.subs = newIndex(subs)
+	ne.Txs = tracked(t)
+
+	log.Printf("[%s] netexplorer.New block:%d bhi:%d addresses:%d txs:%d", net, ne.Block, ne.Bhi, ne.subs.len(),
+		len(ne.Txs))
+
+	return &ne, nil
+}
+
+// listened returns the subscriptions by address of the addresses in 'l' (only for one network) and their expiration
+// times.
+func listened(l []store.ListenedAddresses) (map[string]types.Sub, expiries) {
+	subs := make(map[string]types.Sub)
+
+	var exp expiries
 
 	if len(l) == 1 {
 		for _, a := range l[0].Addr {
-			// TODO, so far we only set a symbolic value "listen", but here we could set more info, such as userId, etc
-			ne.Map[a.Addr] = "listen"
+			subs[a.Addr] = a.Sub
+
+			if a.Sub.Expires > 0 {
+				exp = append(exp, expiry{addr: a.Addr, expires: a.Sub.Expires})
+			}
 		}
 	}
 
-	log.Printf("[%s] netexplorer.New %v %v %v %v", net, ne.Block, ne.Bh, ne.Bhi, ne.Map)
+	heap.Init(&exp)
 
-	return &ne, nil
+	return subs, exp
+}
+
+// tracked returns by hash the transactions in 't' (only for one network).
+func tracked(t []store.ListenedTxs) map[string]store.Tx {
+	txs := make(map[string]store.Tx)
+
+	if len(t) == 1 {
+		for _, tx := range t[0].Txs {
+			txs[tx.Hash] = tx
+		}
+	}
+
+	return txs
+}
+
+// Reload replaces the monitored addresses and transactions with the ones in 'l' and 't', ie. loaded again from DB.
+func (n *NetExplorer) Reload(l []store.ListenedAddresses, t []store.ListenedTxs) {
+	subs, exp := listened(l)
+	txs := tracked(t)
+
+	n.index().load(subs)
+
+	n.l.Lock()
+	n.exp, n.Txs = exp, txs
+	n.l.Unlock()
 }
 
-// ScanTxs detects if the To or From addresses are being monitored within the NetExplorer and if so, includes the
-// transaction in the returned slice.
+// ScanTxs detects if the To or From addresses or the transaction hash are being monitored within the NetExplorer and
+// if so, includes the transaction in the returned slice with the subscriptions it triggers. Address subscriptions are
+// only triggered by the transactions that pass their filters (direction, token and minimum value). Addresses are
+// looked up without locking the NetExplorer, so adding or deleting monitored objects does not delay the scanning of a
+// block. A token transfer sent by a monitored address triggers its subscription once, see tokenCalls.
 func (n *NetExplorer) ScanTxs(txs []types.Trans) (r []types.Trans, err error) {
 	r = make([]types.Trans, 0, 4) // capacity = 4 is more than enough for a block!
 
-	var ok bool
-
 	n.l.Lock()
-	defer n.l.Unlock()
+	tracking := len(n.Txs) > 0
+	n.l.Unlock()
 
 	for _, tx := range txs {
-		if _, ok = n.Map[tx.From].(string); ok {
-			r = append(r, tx)
-		} else if _, ok = n.Map[tx.To].(string); ok {
+		tx.Subs = nil
+
+		if s, ok := n.subs.get(tx.From); ok && s.Matches(tx, true) {
+			s.Obj = tx.From
+			tx.Subs = append(tx.Subs, s)
+		}
+
+		if s, ok := n.subs.get(tx.To); ok && s.Matches(tx, false) && tx.To != tx.From {
+			s.Obj = tx.To
+			tx.Subs = append(tx.Subs, s)
+		}
+
+		if tracking {
+			n.l.Lock()
+			t, ok := n.Txs[tx.Hash]
+			n.l.Unlock()
+
+			if ok {
+				t.Sub.Obj = tx.Hash
+				tx.Subs = append(tx.Subs, t.Sub)
+			}
+		}
+
+		if len(tx.Subs) > 0 {
 			r = append(r, tx)
 		}
 	}
 
-	return
+	return tokenCalls(r), nil
+}
+
+// tokenCalls removes the address subscriptions triggered by a transaction of value 0 (ie. the call to a token contract)
+// when the same transaction has a token transfer triggering them, so they are not informed twice. The transactions
+// with no subscriptions left are removed.
+func tokenCalls(r []types.Trans) []types.Trans {
+	tok := make(map[string]bool) // subscriptions triggered by token transfers, by hash and address
+	for _, tx := range r {
+		if tx.Kind == types.KindToken {
+			for _, s := range tx.Subs {
+				tok[tx.Hash+"/"+s.Obj] = true
+			}
+		}
+	}
+
+	if len(tok) == 0 {
+		return r
+	}
+
+	k := r[:0]
+
+	for _, tx := range r {
+		v, ok := new(big.Int).SetString(strings.TrimPrefix(tx.Value, "0x"), 16)
+		if tx.Kind == "" && ok && v.Sign() == 0 {
+			var subs []types.Sub
+
+			for _, s := range tx.Subs {
+				if s.Obj == tx.Hash || !tok[tx.Hash+"/"+s.Obj] { // monitored transactions are always informed
+					subs = append(subs, s)
+				}
+			}
+
+			if tx.Subs = subs; len(subs) == 0 {
+				continue
+			}
+		}
+
+		k = append(k, tx)
+	}
+
+	return k
 }
 
 // Chained checks if the supplied hash is the last block's hash and so blocks are chained.
@@ -97,7 +244,8 @@ func (n *NetExplorer) Chained(hash string) bool {
 	return n.Bh[n.Bhi] == hash || n.Bh[n.Bhi] == ""
 }
 
-// UpdateChain updates NetExplorer fields with new block hash.
+// UpdateChain updates NetExplorer fields with new block hash. Removed events that have not been included again in a
+// block for longer than the blocks kept are forgotten.
 func (n *NetExplorer) UpdateChain(hash string, maxBlocks int) {
 	n.l.Lock()
 	defer n.l.Unlock()
@@ -105,42 +253,428 @@ func (n *NetExplorer) UpdateChain(hash string, maxBlocks int) {
 	n.Bhi++
 	n.Bhi %= maxBlocks
 	n.Bh[n.Bhi] = hash
+
+	if n.rescan != nil && n.Block >= n.rescan.to && n.seek == nil {
+		n.seek, n.rescan = &n.rescan.back, nil
+	}
+
+	if len(n.Ev) == len(n.Bh) {
+		n.Ev[n.Bhi] = nil
+	}
+
+	for k, tx := range n.Rem {
+		if b, err := strconv.ParseUint(tx.Block, 0, 64); err != nil || b+uint64(maxBlocks) < n.Block {
+			delete(n.Rem, k)
+		}
+	}
+}
+
+// Record keeps the events sent for the last block, so they can be removed if the block is orphaned.
+func (n *NetExplorer) Record(txs []types.Trans) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	if len(n.Ev) != len(n.Bh) {
+		n.Ev = make([][]types.Trans, len(n.Bh))
+	}
+
+	n.Ev[n.Bhi] = txs
+}
+
+// Confirm returns the events sent for the block that is 'depth' blocks deep, as EvConfirmed events. The events are
+// kept in Ev with the blocks in Bh, so depth has to be lower than the number of blocks kept. Blocks are only confirmed
+// once unless they are rewound (see Rewind).
+func (n *NetExplorer) Confirm(depth int) (c []types.Trans) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	if depth < 0 || depth >= len(n.Bh) || len(n.Ev) != len(n.Bh) || n.Block < uint64(depth) ||
+		n.Block-uint64(depth) <= n.Conf {
+		return
+	}
+
+	n.Conf = n.Block - uint64(depth)
+
+	for _, tx := range n.Ev[(n.Bhi-depth+len(n.Bh))%len(n.Bh)] {
+		tx.Event = types.EvConfirmed
+		c = append(c, tx)
+	}
+
+	return
 }
 
-// Add adds an object and its value to the monitoring map.
-func (n *NetExplorer) Add(obj string, value interface{}) {
+// Seen sets the event type of the transactions scanned in the last block to EvSeen, or to EvReemitted if they had
+// been removed by a chain reorganization, informing then the orphaned block they were removed from and the block that
+// replaced it.
+func (n *NetExplorer) Seen(txs []types.Trans) []types.Trans {
 	n.l.Lock()
 	defer n.l.Unlock()
-	n.Map[obj] = value
+
+	for i := range txs {
+		txs[i].Event = types.EvSeen
+
+		if rem, ok := n.Rem[key(txs[i])]; ok {
+			txs[i].Event = types.EvReemitted
+			txs[i].Removed = rem.Removed
+			txs[i].Replaced = rem.Replaced
+
+			delete(n.Rem, key(txs[i]))
+		}
+	}
+
+	return txs
 }
 
-// Del deletes a monitored object from the map returning its value. 'ok' is returned as false if the object was not
+// FindAncestor walks back the blocks kept in Bh comparing their hashes with the hashes of the canonical chain, which
+// are provided by 'canonical' for a given block number. It returns the number of the last block both chains have in
+// common or ErrReorgTooDeep if none of the blocks kept is in the canonical chain. Blocks older than the ones kept in Bh
+// (empty hashes) are considered to be common.
+func (n *NetExplorer) FindAncestor(canonical func(block uint64) (string, error)) (uint64, error) {
+	// take a snapshot so the lock is not held while calling the node
+	n.l.Lock()
+	block, bhi, bh := n.Block, n.Bhi, make([]string, len(n.Bh))
+	copy(bh, n.Bh)
+	n.l.Unlock()
+
+	for d := 0; d < len(bh) && uint64(d) <= block; d++ {
+		hash := bh[(bhi-d+len(bh))%len(bh)]
+		if hash == "" {
+			return block - uint64(d), nil
+		}
+
+		c, err := canonical(block - uint64(d))
+		if err != nil {
+			return 0, fmt.Errorf("cannot get canonical hash for block %d: %w", block-uint64(d), err)
+		}
+
+		if c == hash {
+			return block - uint64(d), nil
+		}
+	}
+
+	return 0, ErrReorgTooDeep
+}
+
+// Rewind moves the explorer back to block number 'block' clearing the hashes kept for the later blocks, so the next
+// block to be parsed is block+1. The block cannot be older than the blocks kept in Bh, in which case ErrReorgTooDeep is
+// returned. Rewinding to the block before the oldest block kept clears all the hashes.
+// The events sent for the orphaned blocks are returned as EvRemoved events, informing the hash of the orphaned block
+// and of the block that replaced it in the canonical chain, given in 'canonical' by block number. The removed events
+// are kept until they are included in a block again (see Seen) or for as long as the blocks kept in Bh.
+func (n *NetExplorer) Rewind(block uint64, canonical map[uint64]string) ([]types.Trans, error) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	if block > n.Block || n.Block-block > uint64(len(n.Bh)) {
+		return nil, ErrReorgTooDeep
+	}
+
+	if len(n.Ev) != len(n.Bh) {
+		n.Ev = make([][]types.Trans, len(n.Bh))
+	}
+
+	if n.Rem == nil {
+		n.Rem = make(map[string]types.Trans)
+	}
+
+	var rem []types.Trans
+
+	if n.Conf > block {
+		n.Conf = block // confirmed events of orphaned blocks are removed, so the new blocks have to be confirmed
+	}
+
+	for ; n.Block > block; n.Block-- {
+		for _, tx := range n.Ev[n.Bhi] {
+			tx.Event = types.EvRemoved
+			tx.Removed = n.Bh[n.Bhi]
+			tx.Replaced = canonical[n.Block]
+			rem = append(rem, tx)
+			n.Rem[key(tx)] = tx
+		}
+
+		n.Bh[n.Bhi], n.Ev[n.Bhi] = "", nil
+		n.Bhi = (n.Bhi - 1 + len(n.Bh)) % len(n.Bh)
+	}
+
+	return rem, nil
+}
+
+// Seek requests the explorer to be moved to block number 'block', so the next block to be parsed is block+1. The move
+// is applied by Move, so the explorer can be moved safely while it is exploring.
+func (n *NetExplorer) Seek(block uint64) {
+	n.l.Lock()
+	defer n.l.Unlock()
+	n.seek, n.rescan = &block, nil
+}
+
+// Rescan requests the explorer to explore again blocks 'from' to 'to' and then to be moved back to the last block
+// parsed, so the blocks after it are parsed next. If 'to' is not before the last block parsed, it is a move to block
+// from-1 (see Seek).
+func (n *NetExplorer) Rescan(from, to uint64) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	back := n.Block
+	if n.seek != nil {
+		back = *n.seek
+	}
+
+	if from > 0 {
+		from--
+	}
+
+	n.seek, n.rescan = &from, nil
+
+	if to < back && from < to {
+		n.rescan = &rescan{to: to, back: back}
+	}
+}
+
+// Move applies the last move requested by Seek, clearing the hashes and events kept as the blocks parsed next are not
+// chained to them. Blocks up to the new block are considered confirmed. Returns the new block and true if the explorer
+// was moved.
+func (n *NetExplorer) Move() (uint64, bool) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	if n.seek == nil {
+		return n.Block, false
+	}
+
+	n.Block, n.Conf, n.Bhi, n.seek = *n.seek, *n.seek, 0, nil
+
+	for i := range n.Bh {
+		n.Bh[i] = ""
+	}
+
+	n.Ev = make([][]types.Trans, len(n.Bh))
+
+	return n.Block, true
+}
+
+// Add adds an object and its subscription record to the monitored addresses.
+func (n *NetExplorer) Add(obj string, value types.Sub) {
+	n.index().set(obj, value)
+
+	if value.Expires > 0 {
+		n.l.Lock()
+		heap.Push(&n.exp, expiry{addr: obj, expires: value.Expires})
+		n.l.Unlock()
+	}
+}
+
+// Expired stops monitoring the addresses whose subscription expires before 'now' (unix time), returning their
+// subscription records.
+func (n *NetExplorer) Expired(now int64) (e []types.Sub) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	for len(n.exp) > 0 && n.exp[0].expires < now {
+		x := heap.Pop(&n.exp).(expiry)
+		// the subscription may have been deleted or replaced after it was pushed
+		if s, ok := n.subs.get(x.addr); !ok || s.Expires != x.expires {
+			continue
+		}
+
+		if s, ok := n.subs.del(x.addr); ok {
+			s.Obj = x.addr
+			e = append(e, s)
+		}
+	}
+
+	return
+}
+
+// Del deletes a monitored object returning its subscription record. 'ok' is returned as false if the object was not
 // being monitored.
-func (n *NetExplorer) Del(obj string) (value interface{}, ok bool) {
+func (n *NetExplorer) Del(obj string) (value types.Sub, ok bool) {
+	return n.index().del(obj)
+}
+
+// Sub returns the subscription record of a monitored address. 'ok' is returned as false if the address is not being
+// monitored.
+func (n *NetExplorer) Sub(obj string) (value types.Sub, ok bool) {
+	return n.subs.get(obj)
+}
+
+// Monitored returns the number of addresses and transactions being monitored.
+func (n *NetExplorer) Monitored() (addrs, txs int) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	return n.subs.len(), len(n.Txs)
+}
+
+// index returns the index of monitored addresses, creating it if the NetExplorer was not created by New.
+func (n *NetExplorer) index() *index {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	if n.subs == nil {
+		n.subs = newIndex(nil)
+	}
+
+	return n.subs
+}
+
+// Track adds a transaction to be monitored until it is confirmed, dropped or untracked.
+func (n *NetExplorer) Track(tx store.Tx) {
 	n.l.Lock()
 	defer n.l.Unlock()
-	value, ok = n.Map[obj]
-	delete(n.Map, obj)
+
+	if n.Txs == nil {
+		n.Txs = make(map[string]store.Tx)
+	}
+
+	n.Txs[tx.Hash] = tx
+}
+
+// Untrack stops monitoring a transaction returning it. 'ok' is returned as false if the transaction was not being
+// monitored.
+func (n *NetExplorer) Untrack(hash string) (tx store.Tx, ok bool) {
+	n.l.Lock()
+	defer n.l.Unlock()
+	tx, ok = n.Txs[hash]
+	delete(n.Txs, hash)
 
 	return
 }
 
+// Tracked checks if a transaction is being monitored.
+func (n *NetExplorer) Tracked(hash string) bool {
+	n.l.Lock()
+	defer n.l.Unlock()
+	_, ok := n.Txs[hash]
+
+	return ok
+}
+
+// Dropped returns as EvDropped events the monitored transactions whose deadline is before 'now' (unix time) and have
+// not been mined, and stops monitoring them. A transaction has been mined if an event was sent for it in any of the
+// blocks kept or removed by a chain reorganization, or if 'onChain' tells so (ie. the node has it in a block not kept
+// by the explorer), in which case it is monitored without deadline. 'onChain' is called without holding the lock.
+func (n *NetExplorer) Dropped(now int64, onChain func(hash string) bool) (d []types.Trans) {
+	var due []string
+
+	n.l.Lock()
+	for hash, tx := range n.Txs {
+		if tx.Deadline != 0 && tx.Deadline < now && !n.mined(hash) {
+			due = append(due, hash)
+		}
+	}
+	n.l.Unlock()
+
+	mined := make(map[string]bool, len(due))
+	for _, hash := range due {
+		mined[hash] = onChain(hash)
+	}
+
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	for _, hash := range due {
+		tx, ok := n.Txs[hash]
+		if !ok || tx.Deadline == 0 || n.mined(hash) {
+			continue // untracked or seen meanwhile
+		}
+
+		if mined[hash] {
+			tx.Deadline = 0
+			n.Txs[hash] = tx
+
+			continue
+		}
+
+		tx.Sub.Obj = hash
+		d = append(d, types.Trans{Hash: hash, Event: types.EvDropped, Subs: []types.Sub{tx.Sub}})
+
+		delete(n.Txs, hash)
+	}
+
+	return
+}
+
+// mined checks if an event was sent for the transaction in any of the blocks kept, or removed by a reorg. Must be
+// called with the lock held.
+func (n *NetExplorer) mined(hash string) bool {
+	for _, ev := range n.Ev {
+		for i := range ev {
+			if ev[i].Hash == hash {
+				return true
+			}
+		}
+	}
+
+	for _, tx := range n.Rem {
+		if tx.Hash == hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key returns the key of an event: its transaction hash, and its log index, trace address or output index for the
+// transfers got from logs, traces or outputs as a transaction may have several.
+func key(tx types.Trans) string {
+	if tx.Trace != "" {
+		return tx.Hash + "/" + tx.Trace
+	}
+
+	if tx.Output != "" {
+		return tx.Hash + ":" + tx.Output
+	}
+
+	return tx.Hash + tx.LogIndex
+}
+
 // ToStore returns a store.NetExplorer struct to be saved to store.
 func (n *NetExplorer) ToStore() store.NetExplorer {
 	return store.NetExplorer{
 		Block: n.Block,
 		Bh:    n.Bh,
 		Bhi:   n.Bhi,
-		Map:   n.Map,
+		Ev:    n.Ev,
+		Conf:  n.Conf,
+		Rem:   n.Rem,
+		Token: n.Fence(),
 	}
 }
 
+// SetFence sets the fencing token of the lease of the explorer.
+func (n *NetExplorer) SetFence(token int64) {
+	n.l.Lock()
+	n.token = token
+	n.l.Unlock()
+}
+
+// Fence returns the fencing token of the lease of the explorer, 0 if leases are not used.
+func (n *NetExplorer) Fence() int64 {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	return n.token
+}
+
 // FromStore loads the NetExplorer with the values read from store.
 func (n *NetExplorer) FromStore(s store.NetExplorer) {
+	n.l.Lock()
+	defer n.l.Unlock()
+
 	n.Block = s.Block
 	n.Bh = s.Bh
 	n.Bhi = s.Bhi
-	n.Map = s.Map
+	n.Ev = s.Ev
+	n.Conf = s.Conf
+	n.Rem = s.Rem
+
+	if len(n.Ev) != len(n.Bh) {
+		n.Ev = make([][]types.Trans, len(n.Bh))
+	}
+
+	if n.Rem == nil {
+		n.Rem = make(map[string]types.Trans)
+	}
 }
 
 // Stop sets status to STOP.
@@ -157,6 +691,32 @@ func (n *NetExplorer) Start() {
 	n.l.Unlock()
 }
 
+// Pause sets status to PAUSE if it is working.
+func (n *NetExplorer) Pause() {
+	n.l.Lock()
+	if n.status == WORK {
+		n.status = PAUSE
+	}
+	n.l.Unlock()
+}
+
+// Resume sets status to WORK if it is paused.
+func (n *NetExplorer) Resume() {
+	n.l.Lock()
+	if n.status == PAUSE {
+		n.status = WORK
+	}
+	n.l.Unlock()
+}
+
+// Cursor returns the last block parsed.
+func (n *NetExplorer) Cursor() uint64 {
+	n.l.Lock()
+	defer n.l.Unlock()
+
+	return n.Block
+}
+
 // Status returns the current NetExplorer status.
 func (n *NetExplorer) Status() int {
 	n.l.Lock()
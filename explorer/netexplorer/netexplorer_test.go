@@ -1,8 +1,14 @@
 package netexplorer
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
 	"testing"
 
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/store"
 	"github.com/tarancss/adp/lib/store/db"
 )
 
@@ -26,7 +32,7 @@ func TestNE(t *testing.T) {
 	var ne *NetExplorer
 
 	var maxBlocks int = 4
-	if ne, err = New("net", maxBlocks, nil, s); err != nil { // listenmap = nil
+	if ne, err = New("net", maxBlocks, nil, nil, nil, s); err != nil { // listenmap = nil
 		t.Errorf("Error creating NetExplorer: %e", err)
 
 		return
@@ -78,20 +84,346 @@ func TestNE(t *testing.T) {
 
 	for _, ts := range tsAddGet {
 		if ts.([]interface{})[0] == "add" {
-			ne.Add(ts.([]interface{})[1].(string), ts.([]interface{})[2])
+			ne.Add(ts.([]interface{})[1].(string), types.Sub{Label: ts.([]interface{})[2].(string)})
 		} else {
 			val, ok := ne.Del(ts.([]interface{})[1].(string))
-			if !ok {
-				val = ""
-			}
-
-			if val.(string) != ts.([]interface{})[2].(string) || ok != ts.([]interface{})[3].(bool) {
+			if val.Label != ts.([]interface{})[2].(string) || ok != ts.([]interface{})[3].(bool) {
 				t.Errorf("Error with %+v", ts)
 			}
 		}
 	}
 	// check final result
-	if len(ne.Map) != 3 {
-		t.Errorf("Error with the Map:%v", ne.Map)
+	if addrs, _ := ne.Monitored(); addrs != 3 {
+		t.Errorf("Error with the monitored addresses:%d", addrs)
+	}
+}
+
+// TestReorg unit tests FindAncestor and Rewind, used to recover from chain reorganizations. It does not require a DB.
+func TestReorg(t *testing.T) {
+	// explorer at block 9 keeping hashes of blocks 6 to 9, with events sent for blocks 8 and 9
+	ne := &NetExplorer{Block: 9, Bhi: 1, Bh: []string{"hash8", "hash9", "hash6", "hash7"},
+		Ev: [][]types.Trans{{{Block: "8", Hash: "tx8"}}, {{Block: "9", Hash: "tx9"}}, nil, nil}}
+
+	// canonical chain forked after block 7
+	canonical := map[uint64]string{9: "hash9bis", 8: "hash8bis", 7: "hash7", 6: "hash6"}
+	getHash := func(n uint64) (string, error) { return canonical[n], nil }
+
+	ancestor, err := ne.FindAncestor(getHash)
+	if err != nil || ancestor != 7 {
+		t.Errorf("FindAncestor returned %d, err:%e", ancestor, err)
+	}
+
+	rem, err := ne.Rewind(ancestor, canonical)
+	if err != nil || ne.Block != 7 || ne.Bhi != 3 || ne.Bh[0] != "" || ne.Bh[1] != "" || !ne.Chained("hash7") {
+		t.Errorf("Rewind error:%e ne:%+v", err, ne)
+	}
+
+	if len(rem) != 2 || rem[0].Hash != "tx9" || rem[0].Event != types.EvRemoved || rem[0].Removed != "hash9" ||
+		rem[0].Replaced != "hash9bis" || rem[1].Hash != "tx8" || rem[1].Removed != "hash8" {
+		t.Errorf("Rewind removed events:%+v", rem)
+	}
+
+	// tx9 is included in block 8bis
+	ne.UpdateChain("hash8bis", 4)
+
+	txs := ne.Seen([]types.Trans{{Block: "8", Hash: "tx9"}, {Block: "8", Hash: "tx10"}})
+	if txs[0].Event != types.EvReemitted || txs[0].Removed != "hash9" || txs[0].Replaced != "hash9bis" ||
+		txs[1].Event != types.EvSeen || len(ne.Rem) != 1 {
+		t.Errorf("Seen events:%+v", txs)
+	}
+
+	// confirm with 1 confirmation: block 7 events are confirmed, then block 8bis events
+	ne.Record(txs)
+
+	if c := ne.Confirm(1); len(c) != 0 || ne.Conf != 7 {
+		t.Errorf("Confirm block 7 events:%+v conf:%d", c, ne.Conf)
+	}
+
+	if c := ne.Confirm(1); len(c) != 0 {
+		t.Errorf("Confirm block 7 again events:%+v", c)
+	}
+
+	ne.UpdateChain("hash9bis", 4)
+
+	if c := ne.Confirm(1); len(c) != 2 || c[0].Hash != "tx9" || c[0].Event != types.EvConfirmed || ne.Conf != 8 {
+		t.Errorf("Confirm block 8bis events:%+v conf:%d", c, ne.Conf)
+	}
+
+	// canonical chain forked before the oldest block kept
+	ne = &NetExplorer{Block: 9, Bhi: 1, Bh: []string{"hash8", "hash9", "hash6", "hash7"}}
+	canonical = map[uint64]string{9: "hash9bis", 8: "hash8bis", 7: "hash7bis", 6: "hash6bis"}
+
+	if _, err = ne.FindAncestor(getHash); !errors.Is(err, ErrReorgTooDeep) {
+		t.Errorf("FindAncestor should have failed, err:%e", err)
+	}
+
+	if _, err = ne.Rewind(4, canonical); !errors.Is(err, ErrReorgTooDeep) {
+		t.Errorf("Rewind should have failed, err:%e", err)
+	}
+
+	if _, err = ne.Rewind(5, canonical); err != nil || ne.Block != 5 || !ne.Chained("anything") {
+		t.Errorf("Rewind error:%e ne:%+v", err, ne)
+	}
+}
+
+// TestTrack unit tests the monitoring of transactions: ScanTxs, Track, Untrack and Dropped. It does not require a DB.
+func TestTrack(t *testing.T) {
+	ne := &NetExplorer{Block: 9, Bhi: 1, Bh: []string{"hash8", "hash9", "hash6", "hash7"},
+		Ev: [][]types.Trans{{{Block: "8", Hash: "tx8"}}, nil, nil, nil}}
+
+	ne.Track(store.Tx{Hash: "tx8", Deadline: 100})
+	ne.Track(store.Tx{Hash: "tx9", Deadline: 100})
+	ne.Track(store.Tx{Hash: "tx10", Deadline: 0})
+	ne.Track(store.Tx{Hash: "tx11", Deadline: 300})
+	ne.Track(store.Tx{Hash: "tx13", Deadline: 100})
+
+	onChain := func(hash string) bool { return hash == "tx13" }
+
+	if r, _ := ne.ScanTxs([]types.Trans{{Hash: "tx9"}, {Hash: "tx12"}}); len(r) != 1 || r[0].Hash != "tx9" {
+		t.Errorf("ScanTxs returned:%+v", r)
+	}
+
+	// tx8 was mined, tx10 has no deadline, tx11 deadline has not been reached and tx13 was mined in a block not kept
+	if d := ne.Dropped(200, onChain); len(d) != 1 || d[0].Hash != "tx9" || d[0].Event != types.EvDropped ||
+		ne.Tracked("tx9") || !ne.Tracked("tx13") || ne.Txs["tx13"].Deadline != 0 {
+		t.Errorf("Dropped returned:%+v", d)
+	}
+
+	if _, ok := ne.Untrack("tx8"); !ok || ne.Tracked("tx8") || len(ne.Txs) != 3 {
+		t.Errorf("Untrack failed, txs:%+v", ne.Txs)
+	}
+}
+
+// TestSeek unit tests moving the explorer with Seek and Move. It does not require a DB.
+func TestSeek(t *testing.T) {
+	ne := &NetExplorer{Block: 9, Bhi: 1, Conf: 9, Bh: []string{"hash8", "hash9", "hash6", "hash7"},
+		Ev: [][]types.Trans{{{Block: "8", Hash: "tx8"}}, nil, nil, nil}}
+
+	if b, ok := ne.Move(); ok || b != 9 {
+		t.Errorf("Move without Seek moved to block %d", b)
+	}
+
+	ne.Seek(100)
+
+	if ne.Block != 9 {
+		t.Errorf("Seek should not move the explorer until Move, block:%d", ne.Block)
+	}
+
+	if b, ok := ne.Move(); !ok || b != 100 || ne.Block != 100 || ne.Conf != 100 || !ne.Chained("anything") ||
+		ne.mined("tx8") {
+		t.Errorf("Move to block %d ne:%+v", b, ne)
+	}
+
+	ne.UpdateChain("hash101", 4)
+
+	if !ne.Chained("hash101") || ne.Block != 101 {
+		t.Errorf("UpdateChain after Move ne:%+v", ne)
+	}
+}
+
+// TestScanTxs unit tests the subscriptions triggered by the transactions scanned. It does not require a DB.
+func TestScanTxs(t *testing.T) {
+	ne := &NetExplorer{subs: newIndex(map[string]types.Sub{
+		"a": {Owner: "user1", Label: "deposits", Dir: types.DirIn},
+		"b": {Owner: "user2", Meta: map[string]string{"id": "2"}},
+		"c": {Owner: "user3", Dir: types.DirOut},
+	})}
+	ne.Track(store.Tx{Hash: "tx4", Sub: types.Sub{Owner: "user4"}})
+
+	r, _ := ne.ScanTxs([]types.Trans{
+		{Hash: "tx1", From: "a", To: "x"}, // a only listens to incoming transactions
+		{Hash: "tx2", From: "x", To: "a"},
+		{Hash: "tx3", From: "b", To: "c"}, // c only listens to outgoing transactions
+		{Hash: "tx4", From: "c", To: "b"},
+	})
+	if len(r) != 3 ||
+		r[0].Hash != "tx2" || len(r[0].Subs) != 1 || r[0].Subs[0].Obj != "a" || r[0].Subs[0].Label != "deposits" ||
+		r[1].Hash != "tx3" || len(r[1].Subs) != 1 || r[1].Subs[0].Obj != "b" || r[1].Subs[0].Meta["id"] != "2" ||
+		r[2].Hash != "tx4" || len(r[2].Subs) != 3 || r[2].Subs[0].Owner != "user3" || r[2].Subs[1].Owner != "user2" ||
+		r[2].Subs[2].Obj != "tx4" || r[2].Subs[2].Owner != "user4" {
+		t.Errorf("ScanTxs error:%+v", r)
+	}
+
+	// token and minimum value filters
+	ne.Add("d", types.Sub{Tokens: []string{"0xtoken1", types.TokNative}, Min: "0x100"})
+	ne.Add("e", types.Sub{Tokens: []string{"0xtoken2"}})
+
+	r, _ = ne.ScanTxs([]types.Trans{
+		{Hash: "tx5", From: "x", To: "d", Value: "0x0100"},                    // native
+		{Hash: "tx6", From: "x", To: "d", Value: "0xff"},                      // dust
+		{Hash: "tx7", From: "x", To: "d", Token: "0xtoken1", Value: "0x1000"}, // token
+		{Hash: "tx8", From: "x", To: "d", Token: "0xtoken2", Value: "0x1000"}, // other token
+		{Hash: "tx9", From: "x", To: "e", Value: "0x1000"},                    // native
+		{Hash: "tx10", From: "x", To: "e", Token: "0xToken2", Value: "0x1"},   // token
+	})
+	if len(r) != 3 || r[0].Hash != "tx5" || r[1].Hash != "tx7" || r[2].Hash != "tx10" {
+		t.Errorf("ScanTxs with filters error:%+v", r)
+	}
+
+	// a token transfer sent by a monitored address is informed once, not also by the call to the token contract
+	r, _ = ne.ScanTxs([]types.Trans{
+		{Hash: "tx11", From: "b", To: "0xtoken1", Value: "0x0"},                                     // call
+		{Hash: "tx11", From: "b", To: "a", Token: "0xtoken1", Value: "0x10", Kind: types.KindToken}, // transfer
+		{Hash: "tx12", From: "b", To: "0xtoken1", Value: "0x0"},                                     // failed transfer
+		{Hash: "tx4", From: "b", To: "0xtoken1", Value: "0x0"},                                      // monitored call
+		{Hash: "tx4", From: "b", To: "x", Token: "0xtoken1", Value: "0x10", Kind: types.KindToken},
+	})
+	if len(r) != 4 || r[0].Hash != "tx11" || r[0].Kind != types.KindToken || len(r[0].Subs) != 2 ||
+		r[1].Hash != "tx12" || r[2].Hash != "tx4" || len(r[2].Subs) != 1 || r[2].Subs[0].Obj != "tx4" ||
+		r[3].Hash != "tx4" || r[3].Kind != types.KindToken {
+		t.Errorf("ScanTxs of a token transfer error:%+v", r)
+	}
+}
+
+// TestIndex unit tests the index of monitored addresses, also while it is being read and written concurrently.
+func TestIndex(t *testing.T) {
+	x := newIndex(map[string]types.Sub{"a": {Label: "a"}})
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				addr := strconv.Itoa(w*1000 + i)
+				x.set(addr, types.Sub{Label: addr})
+
+				if s, ok := x.get(addr); !ok || s.Label != addr {
+					t.Errorf("get error for %s:%+v", addr, s)
+				}
+
+				if i%2 == 0 {
+					if s, ok := x.del(addr); !ok || s.Label != addr {
+						t.Errorf("del error for %s:%+v", addr, s)
+					}
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	if s, ok := x.get("a"); !ok || s.Label != "a" || x.len() != 2001 {
+		t.Errorf("index error:%+v len:%d", s, x.len())
+	}
+
+	if _, ok := x.del("0"); ok {
+		t.Errorf("deleted address should not be found")
+	}
+}
+
+// BenchmarkScanTxs measures the scanning of a block of 200 transactions with 5M monitored addresses.
+func BenchmarkScanTxs(b *testing.B) {
+	subs := make(map[string]types.Sub, 5000000)
+	for i := 0; i < 5000000; i++ {
+		subs[fmt.Sprintf("0x%040x", i)] = types.Sub{}
+	}
+
+	ne := &NetExplorer{subs: newIndex(subs)}
+
+	txs := make([]types.Trans, 200)
+	for i := range txs {
+		txs[i] = types.Trans{From: fmt.Sprintf("0x%040x", i*100000), To: fmt.Sprintf("0x%040x", 7000000+i)}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if r, _ := ne.ScanTxs(txs); len(r) != 50 {
+			b.Fatalf("ScanTxs found %d transactions", len(r))
+		}
+	}
+}
+
+// TestExpired unit tests the expiry of address subscriptions. It does not require a DB.
+func TestExpired(t *testing.T) {
+	ne := &NetExplorer{}
+	ne.Add("a", types.Sub{Expires: 100, Notify: true})
+	ne.Add("b", types.Sub{Expires: 200})
+	ne.Add("c", types.Sub{})
+	ne.Add("d", types.Sub{Expires: 100})
+	ne.Add("d", types.Sub{Expires: 300}) // renewed
+	ne.Add("e", types.Sub{Expires: 50})
+	ne.Del("e")
+
+	if e := ne.Expired(100); len(e) != 0 {
+		t.Errorf("nothing should have expired:%+v", e)
+	}
+
+	if e := ne.Expired(250); len(e) != 2 || e[0].Obj != "a" || !e[0].Notify || e[1].Obj != "b" {
+		t.Errorf("Expired error:%+v", e)
+	}
+
+	if e := ne.Expired(1000); len(e) != 1 || e[0].Obj != "d" {
+		t.Errorf("Expired error:%+v", e)
+	}
+
+	if _, ok := ne.Sub("c"); !ok {
+		t.Errorf("subscription without expiry should not expire")
+	}
+
+	if addrs, _ := ne.Monitored(); addrs != 1 {
+		t.Errorf("monitored addresses error:%d", addrs)
+	}
+}
+
+// TestRescan unit tests the explorer explores again a range of blocks and then returns to the last block parsed.
+func TestRescan(t *testing.T) {
+	ne := &NetExplorer{Block: 20, Conf: 20, Bh: make([]string, 4), Ev: make([][]types.Trans, 4)}
+
+	ne.Rescan(10, 11)
+
+	var parsed []uint64
+
+	for i := 0; i < 6; i++ {
+		ne.Move()
+		ne.UpdateChain("hash", 4)
+		parsed = append(parsed, ne.Block)
+	}
+
+	if fmt.Sprint(parsed) != "[10 11 21 22 23 24]" {
+		t.Errorf("Rescan parsed blocks %v", parsed)
+	}
+	// a rescan up to the last block parsed is a move
+	ne.Rescan(20, 30)
+
+	if b, ok := ne.Move(); !ok || b != 19 || ne.rescan != nil {
+		t.Errorf("Rescan after last block moved to block %d ne:%+v", b, ne)
+	}
+	// a seek cancels a rescan
+	ne.Rescan(5, 6)
+	ne.Seek(50)
+
+	if b, ok := ne.Move(); !ok || b != 50 || ne.rescan != nil {
+		t.Errorf("Seek during rescan moved to block %d ne:%+v", b, ne)
+	}
+}
+
+// TestReload unit tests the monitored objects are replaced by the ones reloaded.
+func TestReload(t *testing.T) {
+	ne := &NetExplorer{subs: newIndex(map[string]types.Sub{"a": {}, "b": {}})}
+	ne.Track(store.Tx{Hash: "tx1"})
+
+	ne.Reload([]store.ListenedAddresses{{Net: "ropsten", Addr: []store.Address{
+		{Addr: "b", Sub: types.Sub{Owner: "user2"}}, {Addr: "c", Sub: types.Sub{Expires: 100}},
+	}}}, []store.ListenedTxs{{Net: "ropsten", Txs: []store.Tx{{Hash: "tx2"}}}})
+
+	if addrs, txs := ne.Monitored(); addrs != 2 || txs != 1 || ne.Tracked("tx1") || !ne.Tracked("tx2") {
+		t.Errorf("Reload monitors %d addresses and %d txs:%+v", addrs, txs, ne.Txs)
+	}
+
+	if _, ok := ne.Sub("a"); ok {
+		t.Errorf("Reload kept address a")
+	}
+
+	if s, ok := ne.Sub("b"); !ok || s.Owner != "user2" {
+		t.Errorf("Reload address b sub:%+v", s)
+	}
+
+	if e := ne.Expired(101); len(e) != 1 || e[0].Obj != "c" {
+		t.Errorf("Reload expired:%+v", e)
 	}
 }
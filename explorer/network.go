@@ -0,0 +1,181 @@
+package explorer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/config"
+	"github.com/tarancss/adp/lib/msg"
+	"github.com/tarancss/adp/lib/store"
+)
+
+// Errors returned adding or removing networks.
+var (
+	ErrNetExists  = errors.New("explorer: network is already explored")
+	ErrNetRequest = errors.New("explorer: invalid network request")
+)
+
+// AddNetwork connects to the blockchain configured in 'conf' and starts exploring it. The network is saved to DB so it
+// is explored after a restart (see store.Networks).
+func (e *Explorer) AddNetwork(conf config.BlockConfig) error {
+	return e.addNetwork(conf, true)
+}
+
+// addNetwork adds the network configured in 'conf', saving it to DB if 'save' is set.
+func (e *Explorer) addNetwork(conf config.BlockConfig, save bool) error {
+	if !msg.NetName(conf.Name) {
+		return fmt.Errorf("explorer: %w: %s", msg.ErrNetName, conf.Name)
+	}
+
+	e.rl.Lock()
+	defer e.rl.Unlock()
+
+	e.nl.RLock()
+	_, ok := e.bc[conf.Name]
+	e.nl.RUnlock()
+
+	if ok {
+		return fmt.Errorf("%w: %s", ErrNetExists, conf.Name)
+	}
+
+	c, err := block.New(conf)
+	if err != nil {
+		return fmt.Errorf("explorer: %w", err)
+	}
+
+	if save {
+		if err = e.db.SaveNetwork(store.Network{Conf: conf}); err != nil {
+			c.Close()
+
+			return fmt.Errorf("explorer: %w", err)
+		}
+	}
+
+	e.nl.Lock()
+	e.bc[conf.Name] = c
+	e.nl.Unlock()
+
+	if err = e.explore(conf.Name, c); err != nil {
+		e.nl.Lock()
+		delete(e.bc, conf.Name)
+		e.nl.Unlock()
+		c.Close()
+
+		return err
+	}
+
+	log.Printf("[%s] Network added", conf.Name)
+
+	return nil
+}
+
+// RemoveNetwork stops exploring blockchain named 'net' and closes its client. The network is saved to DB as removed so
+// it is not explored after a restart, but its monitored addresses and transactions and its explorer are kept in DB in
+// case it is added again. If leases are used, the lease of the network is released.
+func (e *Explorer) RemoveNetwork(net string) error {
+	return e.removeNetwork(net, true)
+}
+
+// removeNetwork removes the network named 'net', saving it to DB as removed if 'save' is set.
+func (e *Explorer) removeNetwork(net string, save bool) error {
+	e.rl.Lock()
+	defer e.rl.Unlock()
+
+	nexp, c, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	if err := e.halt(net, nexp); err != nil {
+		return err
+	}
+
+	if e.ttl > 0 && e.leading(net) {
+		e.stepDown(net, nexp)
+
+		if err := e.db.ReleaseLease(net, e.owner); err != nil {
+			log.Printf("[%s] Cannot release lease, err:%e", net, err)
+		}
+	}
+
+	e.nl.Lock()
+	delete(e.nem, net)
+	delete(e.bc, net)
+	e.nl.Unlock()
+
+	e.l.Lock()
+	delete(e.st, net)
+	e.l.Unlock()
+
+	c.Close()
+
+	if save {
+		if err := e.db.SaveNetwork(store.Network{Conf: config.BlockConfig{Name: net}, Removed: true}); err != nil {
+			return fmt.Errorf("explorer: %w", err)
+		}
+	}
+
+	log.Printf("[%s] Network removed", net)
+
+	return nil
+}
+
+// ManageNetworkRequests starts a go routine to receive and manage the requests to add or remove networks, sent by
+// wallets to msg.NETWORKS.
+func (e *Explorer) ManageNetworkRequests() error {
+	var mut *sync.Mutex = new(sync.Mutex)
+
+	mut.Lock()
+
+	reqCh, errCh, err := e.mb.GetReqs(msg.NETWORKS, mut)
+	if err != nil {
+		return fmt.Errorf("explorer: cannot get requests: %w", err)
+	}
+
+	go func() {
+		log.Printf("Start listening to network request channel")
+
+		for {
+			select {
+			case req, ok := (<-reqCh):
+				if !ok {
+					log.Printf("Stop listening to network request channel")
+
+					return
+				}
+
+				log.Printf("Received network request %+v", req)
+
+				var errReq error
+
+				switch {
+				case req.Type == msg.NET && req.Act == msg.LISTEN && req.Conf != nil && req.Conf.Name == req.Net:
+					errReq = e.AddNetwork(*req.Conf)
+				case req.Type == msg.NET && req.Act == msg.UNLISTEN:
+					errReq = e.RemoveNetwork(req.Net)
+				default:
+					errReq = fmt.Errorf("%w: %+v", ErrNetRequest, req)
+				}
+
+				if errReq != nil {
+					log.Printf("[%s] Network request failed: %e", req.Net, errReq)
+				}
+
+				mut.Unlock()
+			case errCons, ok := (<-errCh):
+				if !ok {
+					log.Printf("Stop listening to network request channel")
+
+					return
+				}
+
+				log.Printf("Received error %+v", errCons)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package explorer
+
+import (
+	"time"
+)
+
+// Limits of the time waited for a new block by a pacer.
+const (
+	paceSamples = 32                     // blocks whose timestamps are used to estimate the block interval
+	pacePolls   = 4                      // polls per block interval once the next block is due
+	paceMin     = 100 * time.Millisecond // minimum time between polls
+)
+
+// pacer estimates the block interval of a network from the timestamps of the last blocks explored, unless it is
+// configured, and tells how long to wait for the next block once the explorer has reached the chain head: until the
+// next block is due, and then polling a few times per block interval so the explorer stays close to the head without
+// hammering the node.
+type pacer struct {
+	avg        time.Duration // block interval, configured or estimated
+	configured bool          // the interval is configured and not estimated
+	blocks     []uint64      // numbers of the last consecutive blocks explored
+	ts         []int64       // timestamps of the last consecutive blocks explored (same index)
+}
+
+// newPacer returns a pacer for a network with the average block interval 'avg', which is estimated from the blocks
+// explored unless it is configured.
+func newPacer(avg time.Duration, configured bool) *pacer {
+	if avg < paceMin {
+		avg = paceMin
+	}
+
+	return &pacer{avg: avg, configured: configured}
+}
+
+// explored records the timestamp (unix time) of block 'n', updating the estimated block interval. The samples are
+// discarded when the blocks are not consecutive (ie. the explorer has been moved or rewound).
+func (p *pacer) explored(n uint64, ts int64) {
+	if l := len(p.blocks); l > 0 && (n != p.blocks[l-1]+1 || ts < p.ts[l-1]) {
+		p.blocks, p.ts = p.blocks[:0], p.ts[:0]
+	}
+
+	p.blocks, p.ts = append(p.blocks, n), append(p.ts, ts)
+	if len(p.blocks) > paceSamples {
+		p.blocks, p.ts = p.blocks[1:], p.ts[1:]
+	}
+
+	l := len(p.blocks) - 1
+	if p.configured || l < 1 || p.ts[l] == p.ts[0] {
+		return
+	}
+	// timestamps are in seconds, averaging over several blocks gives the interval of networks faster than a second
+	avg := time.Duration(p.ts[l]-p.ts[0]) * time.Second / time.Duration(p.blocks[l]-p.blocks[0])
+	if avg < paceMin {
+		avg = paceMin
+	}
+
+	p.avg = avg
+}
+
+// interval returns the block interval, configured or estimated.
+func (p *pacer) interval() time.Duration {
+	return p.avg
+}
+
+// wait returns how long to wait for the next block when it is not available yet at time 'now': the time left until
+// it is due after the last block explored, or a fraction of the block interval if it is already due.
+func (p *pacer) wait(now time.Time) time.Duration {
+	poll := p.avg / pacePolls
+	if poll < paceMin {
+		poll = paceMin
+	}
+
+	if len(p.ts) == 0 {
+		return poll
+	}
+
+	d := time.Unix(p.ts[len(p.ts)-1], 0).Add(p.avg).Sub(now)
+	if d < poll {
+		return poll
+	}
+
+	if d > p.avg {
+		return p.avg // the clocks of the node and ours are not in sync
+	}
+
+	return d
+}
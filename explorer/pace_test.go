@@ -0,0 +1,61 @@
+package explorer
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPacer tests the block interval is estimated from the timestamps of consecutive blocks unless configured, and
+// the time waited for the next block.
+func TestPacer(t *testing.T) {
+	p := newPacer(12*time.Second, false)
+	if d := p.wait(time.Now()); d != 3*time.Second {
+		t.Errorf("wait without blocks explored:%v", d)
+	}
+	// 4 blocks per second, timestamps in seconds
+	for n := uint64(100); n <= 120; n++ {
+		p.explored(n, 1700000000+int64(n-100)/4)
+	}
+
+	if d := p.interval(); d != 250*time.Millisecond {
+		t.Errorf("interval of a fast network:%v", d)
+	}
+	// blocks explored after a rewind are not consecutive, the estimate is kept until there are new samples
+	p.explored(50, 1699999000)
+
+	if d := p.interval(); d != 250*time.Millisecond || len(p.blocks) != 1 {
+		t.Errorf("interval after a rewind:%v samples:%d", d, len(p.blocks))
+	}
+
+	for n := uint64(51); n < 51+2*paceSamples; n++ {
+		p.explored(n, 1699999000+12*int64(n-50))
+	}
+
+	if d := p.interval(); d != 12*time.Second || len(p.blocks) != paceSamples {
+		t.Errorf("interval:%v samples:%d", d, len(p.blocks))
+	}
+	// the next block is due in 10 seconds, then polling each 3 seconds once it is due
+	last := time.Unix(p.ts[len(p.ts)-1], 0)
+	for _, c := range []struct {
+		now  time.Time
+		wait time.Duration
+	}{
+		{last.Add(2 * time.Second), 10 * time.Second},
+		{last.Add(11 * time.Second), 3 * time.Second},
+		{last.Add(time.Minute), 3 * time.Second},
+		{last.Add(-time.Hour), 12 * time.Second},
+	} {
+		if d := p.wait(c.now); d != c.wait {
+			t.Errorf("wait at %v:%v expected:%v", c.now.Sub(last), d, c.wait)
+		}
+	}
+	// a configured interval is not estimated
+	p = newPacer(2*time.Second, true)
+	for n := uint64(1); n < 10; n++ {
+		p.explored(n, 1700000000+int64(n)*12)
+	}
+
+	if d := p.interval(); d != 2*time.Second {
+		t.Errorf("configured interval:%v", d)
+	}
+}
@@ -0,0 +1,226 @@
+package explorer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ne "github.com/tarancss/adp/explorer/netexplorer"
+)
+
+// restartTimeout is the time Restart waits for the go routine exploring a network to end.
+const restartTimeout = time.Minute
+
+// ErrRestartTimeout is returned when the go routine exploring a network does not end in time to be restarted.
+var ErrRestartTimeout = errors.New("explorer: network explorer did not stop in time")
+
+// netState is the runtime state of the exploration of a network.
+type netState struct {
+	running bool          // the go routine exploring the network is running
+	done    chan struct{} // closed when the go routine exploring the network ends
+	head    uint64        // last block known to be mined
+	err     error         // last error
+	errTime int64         // unix time of the last error
+	leader  bool          // the lease of the network is held (see Lease)
+	renewed time.Time     // time the lease was last acquired or renewed
+	avg     time.Duration // block interval, configured or estimated
+}
+
+// Status contains the status of the exploration of a network.
+type Status struct {
+	Net       string  `json:"net"`
+	Block     uint64  `json:"block"`               // last block explored
+	Head      uint64  `json:"head"`                // last block mined
+	Lag       uint64  `json:"lag"`                 // number of blocks mined not explored yet
+	Status    string  `json:"status"`              // WORK, STOP or PAUSE
+	Running   bool    `json:"running"`             // false if the go routine exploring the network has ended
+	Err       string  `json:"lastError,omitempty"` // last error
+	ErrTime   int64   `json:"lastErrorTs,omitempty"`
+	Addresses int     `json:"addresses"`       // number of addresses monitored
+	Txs       int     `json:"txs"`             // number of transactions monitored
+	Lease     string  `json:"lease,omitempty"` // leader or standby, if leases are used
+	BlockTime float64 `json:"blockTime"`       // seconds between blocks, configured or estimated
+}
+
+// state returns the runtime state of a network. Must be called with the lock held.
+func (e *Explorer) state(net string) *netState {
+	s, ok := e.st[net]
+	if !ok {
+		s = &netState{}
+		e.st[net] = s
+	}
+
+	return s
+}
+
+// started records the go routine exploring a network is running.
+func (e *Explorer) started(net string) {
+	e.wg.Add(1)
+
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	s := e.state(net)
+	s.running, s.done = true, make(chan struct{})
+}
+
+// ended records the go routine exploring a network has ended with error 'err'.
+func (e *Explorer) ended(net string, err error) {
+	e.l.Lock()
+	s := e.state(net)
+	s.running = false
+	close(s.done)
+	e.l.Unlock()
+
+	if err != nil {
+		e.fail(net, err)
+	}
+
+	e.wg.Done()
+}
+
+// fail records the last error of a network.
+func (e *Explorer) fail(net string, err error) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	s := e.state(net)
+	s.err, s.errTime = err, time.Now().Unix()
+}
+
+// head records the last block known to be mined in a network.
+func (e *Explorer) head(net string, block uint64) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	if s := e.state(net); block > s.head {
+		s.head = block
+	}
+}
+
+// paced records the block interval of a network.
+func (e *Explorer) paced(net string, avg time.Duration) {
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	e.state(net).avg = avg
+}
+
+// Status returns the status of the exploration of blockchain named 'net'. The last block mined is requested to the
+// node.
+func (e *Explorer) Status(net string) (Status, error) {
+	nexp, c, ok := e.network(net)
+	if !ok {
+		return Status{}, ErrUnknownNet
+	}
+
+	if latest, err := c.Latest(); err == nil {
+		e.head(net, latest)
+	}
+
+	st := Status{Net: net, Block: nexp.Cursor()}
+	st.Addresses, st.Txs = nexp.Monitored()
+
+	switch nexp.Status() {
+	case ne.WORK:
+		st.Status = "WORK"
+	case ne.STOP:
+		st.Status = "STOP"
+	case ne.PAUSE:
+		st.Status = "PAUSE"
+	}
+
+	e.l.Lock()
+	defer e.l.Unlock()
+
+	s := e.state(net)
+	st.Head, st.Running, st.BlockTime = s.head, s.running, s.avg.Seconds()
+
+	if st.Head > st.Block {
+		st.Lag = st.Head - st.Block
+	}
+
+	if s.err != nil {
+		st.Err, st.ErrTime = s.err.Error(), s.errTime
+	}
+
+	if e.ttl > 0 {
+		st.Lease = "standby"
+		if s.leader {
+			st.Lease = "leader"
+		}
+	}
+
+	return st, nil
+}
+
+// Pause pauses the exploration of blockchain named 'net', the explorer keeps processing wallet requests.
+func (e *Explorer) Pause(net string) error {
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	nexp.Pause()
+
+	return nil
+}
+
+// Resume resumes the exploration of blockchain named 'net' if it was paused.
+func (e *Explorer) Resume(net string) error {
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	nexp.Resume()
+
+	return nil
+}
+
+// Restart stops the go routine exploring blockchain named 'net', if it is running, and starts a new one from the last
+// block explored. It can be used to recover a network whose explorer has stopped after an error. A network explored by
+// another explorer cannot be restarted.
+func (e *Explorer) Restart(net string) error {
+	nexp, _, ok := e.network(net)
+	if !ok {
+		return ErrUnknownNet
+	}
+
+	e.rl.Lock()
+	defer e.rl.Unlock()
+
+	if !e.leading(net) {
+		return fmt.Errorf("%w: %s", ErrStandby, net)
+	}
+
+	if err := e.halt(net, nexp); err != nil {
+		return err
+	}
+
+	nexp.Start()
+	e.ExploreChain(net, e.ret)
+
+	return nil
+}
+
+// halt stops the go routine exploring blockchain named 'net', if it is running, and waits for it to end. Must be called
+// with rl held.
+func (e *Explorer) halt(net string, nexp *ne.NetExplorer) error {
+	e.l.Lock()
+	s := e.state(net)
+	running, done := s.running, s.done
+	e.l.Unlock()
+
+	if running {
+		nexp.Stop()
+
+		select {
+		case <-done:
+		case <-time.After(restartTimeout):
+			return fmt.Errorf("%w: %s", ErrRestartTimeout, net)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,787 @@
+// Package bitcoin implements interface for bitcoin networks, using the JSON-RPC API of a Bitcoin Core node.
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
+)
+
+// Names of the bitcoin networks, see config.BlockConfig.
+const (
+	MainNet = "bitcoin"
+	TestNet = "bitcoinTestnet"
+	RegTest = "bitcoinRegtest"
+)
+
+// Errors returned by the bitcoin interface.
+var (
+	ErrNetwork = errors.New("bitcoin: unknown network")
+	ErrToken   = errors.New("bitcoin: tokens are not supported")
+	ErrData    = errors.New("bitcoin: sending data is not supported")
+	ErrAmount  = errors.New("bitcoin: invalid amount")
+	ErrDust    = errors.New("bitcoin: amount is below the dust limit")
+	ErrFunds   = errors.New("bitcoin: insufficient funds")
+	ErrKey     = errors.New("bitcoin: invalid key for the address")
+)
+
+// Sizes in virtual bytes of a transaction spending P2WPKH outputs, used to calculate its fee.
+const (
+	txOverhead = 11  // version, locktime, segwit marker and counts of inputs and outputs (rounded up)
+	inputSize  = 68  // outpoint, sequence and witness of a P2WPKH input (rounded up)
+	outputSize = 31  // value and script of a P2WPKH output
+	dust       = 294 // minimum value of a P2WPKH output relayed by the nodes
+	minFeeRate = 1   // minimum fee rate relayed by the nodes in sat/vB
+	feeBlocks  = 6   // blocks to confirm a transaction within used to estimate the fee rate
+)
+
+// Params returns the chain parameters of the bitcoin network named 'net'.
+func Params(net string) (*chaincfg.Params, bool) {
+	switch net {
+	case MainNet:
+		return &chaincfg.MainNetParams, true
+	case TestNet:
+		return &chaincfg.TestNet3Params, true
+	case RegTest:
+		return &chaincfg.RegressionNetParams, true
+	}
+
+	return nil, false
+}
+
+// Bitcoin implements a connection to a bitcoin network.
+type Bitcoin struct {
+	c      *client
+	conf   config.BlockConfig
+	params *chaincfg.Params
+	scan   sync.Mutex             // the node scans its UTXO set for one request at a time
+	l      sync.Mutex             // l protects locks
+	locks  map[string]*sync.Mutex // locks of the addresses sending, so their outputs are spent once
+}
+
+// Init returns a connection to a Bitcoin Core node given in the blockchain configuration, using its secret
+// (user:password) if necessary for authentication. The network is given by the name of the configuration (see MainNet,
+// TestNet and RegTest). The node has to be version 23 or later to decode the senders of the transactions.
+func Init(conf config.BlockConfig) (*Bitcoin, error) {
+	params, ok := Params(conf.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNetwork, conf.Name)
+	}
+
+	return &Bitcoin{c: newClient(conf.Node, conf.Secret), conf: conf, params: params,
+		locks: make(map[string]*sync.Mutex)}, nil
+}
+
+// MaxBlocks returns how many blocks will be taken into account for orphan management.
+func (b *Bitcoin) MaxBlocks() int {
+	return b.conf.MaxBlocks
+}
+
+// Confirmations returns how many blocks have to be mined on top of a block for its transactions to be confirmed.
+func (b *Bitcoin) Confirmations() int {
+	return b.conf.Confirmations
+}
+
+// Rate returns the maximum number of blocks requested per second to the node, 1 by default.
+func (b *Bitcoin) Rate() int {
+	if b.conf.Rate < 1 {
+		return 1
+	}
+
+	return b.conf.Rate
+}
+
+// Window returns the number of blocks that can be requested concurrently to the node, 1 by default.
+func (b *Bitcoin) Window() int {
+	if b.conf.Window < 1 {
+		return 1
+	}
+
+	return b.conf.Window
+}
+
+// StartBlock returns the first block to be explored for a new network.
+func (b *Bitcoin) StartBlock() string {
+	return b.conf.StartBlock
+}
+
+// Native returns the native currency of the network, BTC with 8 decimals unless configured.
+func (b *Bitcoin) Native() types.Token {
+	t := types.Token{Name: b.conf.Name, Symbol: b.conf.Symbol, Decimals: b.conf.Decimals}
+	if t.Symbol == "" {
+		t.Symbol = "BTC"
+	}
+
+	if t.Decimals == 0 {
+		t.Decimals = 8
+	}
+
+	return t
+}
+
+// AvgBlock returns the average time to mine a block configured, or 10 minutes and false if it is not configured and
+// has to be estimated.
+func (b *Bitcoin) AvgBlock() (time.Duration, bool) {
+	if b.conf.BlockTime > 0 {
+		return time.Duration(b.conf.BlockTime * float64(time.Second)), true
+	}
+
+	return 600 * time.Second, false //nolint:gomnd // default block time
+}
+
+// Close ends a connection.
+func (b *Bitcoin) Close() {
+	b.c.hc.CloseIdleConnections()
+}
+
+// Balance returns the balance in satoshis of an address, which is the sum of its unspent outputs. Tokens are not
+// supported, so the token balance is always 0.
+func (b *Bitcoin) Balance(address, token string) (bal, tokBal *big.Int, err error) {
+	if token != "" {
+		return nil, nil, ErrToken
+	}
+
+	utxos, err := b.unspent(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bal = new(big.Int)
+	for _, u := range utxos {
+		bal.Add(bal, big.NewInt(u.value))
+	}
+
+	return bal, new(big.Int), nil
+}
+
+// utxo is an unspent transaction output.
+type utxo struct {
+	TxID   string      `json:"txid"`
+	Vout   uint32      `json:"vout"`
+	Script string      `json:"scriptPubKey"`
+	Amount json.Number `json:"amount"`
+	value  int64       // amount in satoshis
+}
+
+// unspent returns the unspent outputs of an address in the node's UTXO set, so the address does not have to be in the
+// node's wallet. The UTXO set only has the outputs of the transactions mined, see spendable.
+func (b *Bitcoin) unspent(address string) ([]utxo, error) {
+	var r struct {
+		Unspents []utxo `json:"unspents"`
+	}
+
+	b.scan.Lock()
+	err := b.c.call("scantxoutset", []interface{}{"start", []string{"addr(" + address + ")"}}, &r)
+	b.scan.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot get unspent outputs of %s: %w", address, err)
+	}
+
+	for i := range r.Unspents {
+		if r.Unspents[i].value, err = sats(r.Unspents[i].Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.Unspents, nil
+}
+
+// spendable returns the unspent outputs of 'utxos' not spent by a transaction in the node's mempool, so a transaction
+// not mined yet is not double spent.
+func (b *Bitcoin) spendable(utxos []utxo) ([]utxo, error) {
+	var s []utxo
+
+	for _, u := range utxos {
+		var out *struct {
+			Value json.Number `json:"value"`
+		}
+
+		if err := b.c.call("gettxout", []interface{}{u.TxID, u.Vout, true}, &out); err != nil {
+			return nil, fmt.Errorf("cannot get output %s:%d: %w", u.TxID, u.Vout, err)
+		}
+
+		if out != nil { // the output is spent otherwise
+			s = append(s, u)
+		}
+	}
+
+	return s, nil
+}
+
+// lock locks the address 'addr' so its outputs are spent by one transaction at a time, returning the function that
+// unlocks it.
+func (b *Bitcoin) lock(addr string) func() {
+	b.l.Lock()
+
+	l, ok := b.locks[addr]
+	if !ok {
+		l = &sync.Mutex{}
+		b.locks[addr] = l
+	}
+	b.l.Unlock()
+
+	l.Lock()
+
+	return l.Unlock
+}
+
+// Latest returns the number of the latest block mined.
+func (b *Bitcoin) Latest() (uint64, error) {
+	var n uint64
+	if err := b.c.call("getblockcount", nil, &n); err != nil {
+		return 0, fmt.Errorf("cannot get latest block: %w", err)
+	}
+
+	return n, nil
+}
+
+// GetBlock returns in response the block number requested. If full, it provides all the details of the transactions,
+// including the outputs spent by their inputs.
+func (b *Bitcoin) GetBlock(block uint64, full bool, response interface{}) error {
+	var hash string
+
+	if err := b.c.call("getblockhash", []interface{}{block}, &hash); err != nil {
+		if code(err) == errInvalidParameter {
+			return types.ErrNoBlock
+		}
+
+		return fmt.Errorf("cannot get hash of block %d: %w", block, err)
+	}
+
+	verbosity := 1
+	if full {
+		verbosity = 3
+	}
+
+	if err := b.c.call("getblock", []interface{}{hash, verbosity}, response.(*map[string]interface{})); err != nil {
+		return fmt.Errorf("cannot get block %d: %w", block, err)
+	}
+
+	return nil
+}
+
+// DecodeBlock returns a struct with the values from the block data. It is used after a call to GetBlock.
+func (b *Bitcoin) DecodeBlock(t interface{}) (types.Block, error) {
+	m, ok := t.(map[string]interface{})
+	if !ok {
+		return types.Block{}, types.ErrBlockDecode
+	}
+
+	hash, ok := m["hash"].(string)
+	if !ok {
+		return types.Block{}, types.ErrNoHash
+	}
+
+	number, ok := m["height"].(json.Number)
+	if !ok {
+		return types.Block{}, types.ErrNoBlockNumber
+	}
+
+	n, err := strconv.ParseUint(string(number), 10, 64)
+	if err != nil {
+		return types.Block{}, types.ErrNoBlockNumber
+	}
+	// the genesis block does not have a parent
+	pHash, ok := m["previousblockhash"].(string)
+	if !ok && n > 0 {
+		return types.Block{}, types.ErrNoParentHash
+	}
+
+	ts, ok := m["time"].(json.Number)
+	if !ok {
+		return types.Block{}, types.ErrNoTS
+	}
+
+	sec, err := strconv.ParseUint(string(ts), 10, 32)
+	if err != nil {
+		return types.Block{}, types.ErrNoTS
+	}
+
+	return types.Block{
+		Hash: hash, PHash: pHash, Number: "0x" + strconv.FormatUint(n, 16), TS: "0x" + strconv.FormatUint(sec, 16),
+	}, nil
+}
+
+// DecodeTxs returns a slice of transactions from the block data, one for each output with an address of the block's
+// transactions, so the outputs paid to any address are detected. The sender of the outputs is the address of the
+// output spent by the first input of their transaction, empty for coinbase transactions. It is used after a call to
+// GetBlock.
+func (b *Bitcoin) DecodeTxs(t interface{}) (txs []types.Trans, err error) {
+	m, ok := t.(map[string]interface{})
+	if !ok {
+		return nil, types.ErrNoTrx
+	}
+
+	txList, ok := m["tx"].([]interface{})
+	if !ok {
+		return nil, types.ErrNoTrx
+	}
+
+	blk, err := b.DecodeBlock(m)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range txList {
+		tx, ok := t.(map[string]interface{})
+		if !ok {
+			// only transaction ids
+			if id, ok := t.(string); ok {
+				txs = append(txs, types.Trans{Block: blk.Number, Hash: id})
+
+				continue
+			}
+
+			return nil, types.ErrNoTrx
+		}
+
+		id, ok := tx["txid"].(string)
+		if !ok {
+			return nil, types.ErrNoTrxHash
+		}
+
+		outs, ok := tx["vout"].([]interface{})
+		if !ok {
+			return nil, types.ErrNoTrxValue
+		}
+		// the fee is not informed for coinbase transactions, price is the fee rate in sat/vB
+		var fee, price int64
+		if _, ok = tx["fee"]; ok {
+			if fee, err = sats(tx["fee"]); err != nil {
+				return nil, err
+			}
+
+			if vsize, errSize := strconv.ParseInt(fmt.Sprint(tx["vsize"]), 10, 64); errSize == nil && vsize > 0 {
+				price = fee / vsize
+			}
+		}
+
+		from := sender(tx)
+
+		for _, o := range outs {
+			out, ok := o.(map[string]interface{})
+			if !ok {
+				return nil, types.ErrNoTrxValue
+			}
+
+			to := address(out["scriptPubKey"])
+			if to == "" {
+				continue // OP_RETURN and non-standard outputs do not pay to an address
+			}
+
+			value, err := sats(out["value"])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", types.ErrNoTrxValue, id)
+			}
+
+			txs = append(txs, types.Trans{
+				Block:  blk.Number,
+				Hash:   id,
+				From:   from,
+				To:     to,
+				Value:  "0x" + strconv.FormatInt(value, 16),
+				Output: fmt.Sprint(out["n"]),
+				Price:  uint64(price),
+				Fee:    uint64(fee),
+				Status: types.TxPending,
+			})
+		}
+	}
+
+	return txs, nil
+}
+
+// sender returns the address of the output spent by the first input of a transaction, empty if it is a coinbase
+// transaction or the spent output is not informed by the node. A transaction spending the outputs of several addresses
+// is only informed as sent by the address of its first input, so the other addresses are not notified as senders.
+func sender(tx map[string]interface{}) string {
+	ins, _ := tx["vin"].([]interface{})
+	if len(ins) == 0 {
+		return ""
+	}
+
+	in, _ := ins[0].(map[string]interface{})
+	prev, _ := in["prevout"].(map[string]interface{})
+
+	return address(prev["scriptPubKey"])
+}
+
+// address returns the address of an output script, empty if it does not pay to an address.
+func address(script interface{}) string {
+	s, _ := script.(map[string]interface{})
+	if a, ok := s["address"].(string); ok {
+		return a
+	}
+	// nodes previous to version 22 inform a list of addresses
+	if as, ok := s["addresses"].([]interface{}); ok && len(as) == 1 {
+		a, _ := as[0].(string)
+
+		return a
+	}
+
+	return ""
+}
+
+// Transfers returns no transfers as tokens are not supported.
+func (b *Bitcoin) Transfers(blk types.Block) ([]types.Trans, error) {
+	return nil, nil
+}
+
+// GetToken returns ErrToken as tokens are not supported.
+func (b *Bitcoin) GetToken(token string) (types.Token, error) {
+	return types.Token{}, ErrToken
+}
+
+// Send sends 'amount' satoshis to 'toAddress' from the P2WPKH address of 'key', sending the change back to
+// 'fromAddress' (see SendChange). The price of the fees is the fee rate in sat/vB, estimated by the node if not set.
+func (b *Bitcoin) Send(fromAddress, toAddress, token, amount string, data []byte, key string, fees *types.Fees,
+	dryRun bool) (fee *big.Int, hash []byte, err error) {
+	if token != "" {
+		return new(big.Int), nil, ErrToken
+	}
+
+	if len(data) > 0 {
+		return new(big.Int), nil, ErrData
+	}
+
+	if fees.Dynamic() {
+		return new(big.Int), nil, fmt.Errorf("%w: bitcoin does not support dynamic fees", types.ErrFees)
+	}
+
+	if fees.Price == 0 {
+		fees.Price = uint64(b.feeRate())
+	}
+
+	return b.SendChange(fromAddress, toAddress, fromAddress, amount, key, fees.Price, dryRun)
+}
+
+// SendChange sends 'amount' satoshis (decimal or 0x-hexadecimal) to 'to' spending the unspent outputs of 'from', which
+// has to be the P2WPKH address of the private key 'key' (hexadecimal), and sends the change to 'change'. The largest
+// outputs mined and not spent in the mempool are spent first, by one transaction from 'from' at a time, so the change
+// of a transaction is not spent until it is mined. The fee is calculated at 'feeRate' sat/vB, or the rate estimated by
+// the node if 0. It returns the fee paid and the transaction id, or an error otherwise. If 'dryRun' is true, the
+// transaction will not be sent to the blockchain but still a valid transaction id will be returned.
+func (b *Bitcoin) SendChange(from, to, change, amount, key string, feeRate uint64, dryRun bool) (fee *big.Int,
+	hash []byte, err error) {
+	fee = new(big.Int)
+
+	prv, err := privKey(key)
+	if err != nil {
+		return
+	}
+
+	if b.keyAddress(prv) != from {
+		return fee, nil, fmt.Errorf("%w: %s", ErrKey, from)
+	}
+
+	value, err := strconv.ParseInt(amount, 0, 64)
+	if err != nil || value <= 0 {
+		return fee, nil, fmt.Errorf("%w: %s", ErrAmount, amount)
+	}
+
+	if value < dust {
+		return fee, nil, fmt.Errorf("%w: %d", ErrDust, value)
+	}
+
+	toScript, err := b.script(to)
+	if err != nil {
+		return
+	}
+
+	changeScript, err := b.script(change)
+	if err != nil {
+		return
+	}
+
+	rate := int64(feeRate)
+	if rate == 0 {
+		rate = b.feeRate()
+	}
+
+	defer b.lock(from)()
+
+	utxos, err := b.unspent(from)
+	if err != nil {
+		return
+	}
+
+	if utxos, err = b.spendable(utxos); err != nil {
+		return
+	}
+
+	sel, rest, paid, err := selectCoins(utxos, value, rate)
+	if err != nil {
+		return
+	}
+
+	outs := []*wire.TxOut{wire.NewTxOut(value, toScript)}
+	if rest > 0 {
+		outs = append(outs, wire.NewTxOut(rest, changeScript))
+	}
+
+	tx, err := sign(sel, outs, prv)
+	if err != nil {
+		return
+	}
+
+	var raw bytes.Buffer
+	if err = tx.Serialize(&raw); err != nil {
+		return fee, nil, fmt.Errorf("cannot serialize transaction: %w", err)
+	}
+
+	txid := tx.TxHash().String()
+
+	if !dryRun {
+		if err = b.c.call("sendrawtransaction", []interface{}{hex.EncodeToString(raw.Bytes())}, &txid); err != nil {
+			return fee, nil, fmt.Errorf("cannot send transaction: %w", err)
+		}
+	}
+
+	hash, _ = hex.DecodeString(txid)
+
+	return fee.SetInt64(paid), hash, nil
+}
+
+// selectCoins selects the largest unspent outputs first until they pay 'value' and the fee of the transaction at
+// 'feeRate' sat/vB. It returns the outputs selected, the change and the fee paid. If the change would be dust, it is
+// not sent and is paid as fee.
+func selectCoins(utxos []utxo, value, feeRate int64) (sel []utxo, change, fee int64, err error) {
+	sort.Slice(utxos, func(i, j int) bool { return utxos[i].value > utxos[j].value })
+
+	var total int64
+
+	for _, u := range utxos {
+		sel = append(sel, u)
+		total += u.value
+		// without change output
+		if total < value+int64(txOverhead+inputSize*len(sel)+outputSize)*feeRate {
+			continue
+		}
+
+		fee = int64(txOverhead+inputSize*len(sel)+outputSize*2) * feeRate //nolint:gomnd // payment and change
+		if change = total - value - fee; change < dust {
+			return sel, 0, total - value, nil
+		}
+
+		return sel, change, fee, nil
+	}
+
+	return nil, 0, 0, fmt.Errorf("%w: %d satoshis available", ErrFunds, total)
+}
+
+// sign returns a transaction spending the P2WPKH outputs 'utxos' of the private key 'prv' to the outputs 'outs'. The
+// inputs signal replaceability (BIP125) so the fee can be bumped.
+func sign(utxos []utxo, outs []*wire.TxOut, prv *btcec.PrivateKey) (*wire.MsgTx, error) {
+	tx := wire.NewMsgTx(wire.TxVersion + 1) //nolint:gomnd // version 2 (BIP68)
+	prev := txscript.NewMultiPrevOutFetcher(nil)
+
+	for _, u := range utxos {
+		h, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unspent output %s: %w", u.TxID, err)
+		}
+
+		script, err := hex.DecodeString(u.Script)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script of unspent output %s: %w", u.TxID, err)
+		}
+
+		in := wire.NewTxIn(wire.NewOutPoint(h, u.Vout), nil, nil)
+		in.Sequence = wire.MaxTxInSequenceNum - 2 //nolint:gomnd // replaceable
+		tx.AddTxIn(in)
+		prev.AddPrevOut(in.PreviousOutPoint, wire.NewTxOut(u.value, script))
+	}
+
+	for _, out := range outs {
+		tx.AddTxOut(out)
+	}
+
+	hashes := txscript.NewTxSigHashes(tx, prev)
+
+	for i, in := range tx.TxIn {
+		out := prev.FetchPrevOutput(in.PreviousOutPoint)
+
+		w, err := txscript.WitnessSignature(tx, hashes, i, out.Value, out.PkScript, txscript.SigHashAll, prv, true)
+		if err != nil {
+			return nil, fmt.Errorf("cannot sign input %d: %w", i, err)
+		}
+
+		in.Witness = w
+	}
+
+	return tx, nil
+}
+
+// feeRate returns the fee rate in sat/vB estimated by the node to confirm a transaction within feeBlocks blocks, or
+// minFeeRate if the node cannot estimate it (ie. in regtest).
+func (b *Bitcoin) feeRate() int64 {
+	var r struct {
+		FeeRate json.Number `json:"feerate"` // in BTC/kvB
+		Errors  []string    `json:"errors"`
+	}
+
+	if err := b.c.call("estimatesmartfee", []interface{}{feeBlocks}, &r); err != nil || r.FeeRate == "" {
+		log.Printf("bitcoin: cannot estimate fee rate, using %d sat/vB, err:%v %v", minFeeRate, err, r.Errors)
+
+		return minFeeRate
+	}
+
+	kvb, err := sats(r.FeeRate)
+	if err != nil {
+		return minFeeRate
+	}
+
+	if rate := (kvb + 999) / 1000; rate > minFeeRate { //nolint:gomnd // sat/kvB to sat/vB rounding up
+		return rate
+	}
+
+	return minFeeRate
+}
+
+// script returns the output script paying to an address.
+func (b *Bitcoin) script(addr string) ([]byte, error) {
+	a, err := b.decode(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := txscript.PayToAddrScript(a)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", types.ErrAddress, addr, err)
+	}
+
+	return s, nil
+}
+
+// Receipt sets the status of a mined transaction, which is successful as the transactions of a valid block cannot
+// fail. The fee is decoded from the block by DecodeTxs.
+func (b *Bitcoin) Receipt(tx *types.Trans) error {
+	tx.Status = types.TxSuccess
+
+	return nil
+}
+
+// Get returns the details of the transaction for the given id: its block, status and its first output paying to an
+// address (the payment of the transactions sent by SendChange). The node has to maintain a transaction index (txindex)
+// to get mined transactions that are not in its wallet.
+func (b *Bitcoin) Get(hash string) (*types.Trans, error) {
+	var r struct {
+		TxID      string                   `json:"txid"`
+		BlockHash string                   `json:"blockhash"`
+		Conf      int64                    `json:"confirmations"`
+		BlockTime int64                    `json:"blocktime"`
+		Vout      []map[string]interface{} `json:"vout"`
+	}
+
+	if err := b.c.call("getrawtransaction", []interface{}{hash, true}, &r); err != nil {
+		if code(err) == errInvalidAddressOrKey {
+			return nil, fmt.Errorf("%w: %s", types.ErrNoTrx, hash)
+		}
+
+		return nil, fmt.Errorf("cannot get transaction for hash %s: %w", hash, err)
+	}
+
+	tx := &types.Trans{Hash: r.TxID, BlockHash: r.BlockHash, Status: types.TxPending, TS: uint32(r.BlockTime)}
+
+	if r.Conf > 0 {
+		var h struct {
+			Height uint64 `json:"height"`
+		}
+
+		if err := b.c.call("getblockheader", []interface{}{r.BlockHash}, &h); err != nil {
+			return nil, fmt.Errorf("cannot get block %s: %w", r.BlockHash, err)
+		}
+
+		tx.Block, tx.Status = "0x"+strconv.FormatUint(h.Height, 16), types.TxSuccess
+	}
+
+	for _, out := range r.Vout {
+		if tx.To = address(out["scriptPubKey"]); tx.To != "" {
+			value, err := sats(out["value"])
+			if err != nil {
+				return nil, err
+			}
+
+			tx.Value, tx.Output = "0x"+strconv.FormatInt(value, 16), fmt.Sprint(out["n"])
+
+			break
+		}
+	}
+
+	return tx, nil
+}
+
+// Address validates an address of the network returning its canonical form: bech32 addresses are lowercased and
+// base58 addresses are kept as they are.
+func (b *Bitcoin) Address(addr string) (string, error) {
+	a, err := b.decode(addr)
+	if err != nil {
+		return "", err
+	}
+
+	return a.EncodeAddress(), nil
+}
+
+// decode decodes an address of the network.
+func (b *Bitcoin) decode(addr string) (btcutil.Address, error) {
+	a, err := btcutil.DecodeAddress(addr, b.params)
+	if err != nil || !a.IsForNet(b.params) {
+		return nil, fmt.Errorf("%w: %s is not a %s address", types.ErrAddress, addr, b.conf.Name)
+	}
+
+	return a, nil
+}
+
+// Checksum returns the canonical form of an address, as bitcoin addresses have their own checksum.
+func (b *Bitcoin) Checksum(addr string) string {
+	return addr
+}
+
+// KeyAddress returns the P2WPKH (native segwit) address of the private key 'key' (hexadecimal), ie. of an HD wallet.
+func (b *Bitcoin) KeyAddress(key string) (string, error) {
+	prv, err := privKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return b.keyAddress(prv), nil
+}
+
+// keyAddress returns the P2WPKH address of a private key.
+func (b *Bitcoin) keyAddress(prv *btcec.PrivateKey) string {
+	a, _ := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(prv.PubKey().SerializeCompressed()), b.params)
+
+	return a.EncodeAddress()
+}
+
+// privKey decodes a hexadecimal private key.
+func privKey(key string) (*btcec.PrivateKey, error) {
+	k, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+	if err != nil || len(k) != btcec.PrivKeyBytesLen {
+		return nil, ErrKey
+	}
+
+	prv, _ := btcec.PrivKeyFromBytes(k)
+
+	return prv, nil
+}
@@ -0,0 +1,311 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
+)
+
+// keys of the HD wallet addresses used in the tests.
+const (
+	key1 = "0101010101010101010101010101010101010101010101010101010101010101"
+	key2 = "0202020202020202020202020202020202020202020202020202020202020202"
+)
+
+// node is a mock bitcoind replying the results of the methods called, or an error if the result is an *rpcError.
+type node struct {
+	results map[string]interface{}
+	sent    string          // raw transaction sent
+	spent   map[string]bool // outputs spent by the transactions sent, by txid:vout
+}
+
+func (n *node) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     uint64        `json:"id"`
+		Method string        `json:"method"`
+		Params []interface{} `json:"params"`
+	}
+
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	res := map[string]interface{}{"id": req.ID, "result": nil, "error": nil}
+
+	switch req.Method {
+	case "getblockhash":
+		if req.Params[0].(float64) > 120 {
+			rw.WriteHeader(http.StatusInternalServerError)
+
+			res["error"] = &rpcError{Code: errInvalidParameter, Message: "Block height out of range"}
+		} else {
+			res["result"] = "0000000000000000000000000000000000000000000000000000000000000120"
+		}
+	case "sendrawtransaction":
+		n.sent = req.Params[0].(string)
+		tx := wire.NewMsgTx(0)
+		_ = tx.Deserialize(hex.NewDecoder(strings.NewReader(n.sent)))
+		res["result"] = tx.TxHash().String()
+
+		for _, in := range tx.TxIn {
+			n.spent[in.PreviousOutPoint.String()] = true
+		}
+	case "gettxout":
+		if !n.spent[fmt.Sprintf("%s:%.0f", req.Params[0], req.Params[1])] {
+			res["result"] = json.RawMessage(`{"value":0.001}`)
+		}
+	default:
+		if e, ok := n.results[req.Method].(*rpcError); ok {
+			rw.WriteHeader(http.StatusInternalServerError)
+
+			res["error"] = e
+		} else {
+			res["result"] = json.RawMessage(n.results[req.Method].(string))
+		}
+	}
+
+	_ = json.NewEncoder(rw).Encode(res)
+}
+
+// regtest returns a client of a mock regtest node and the addresses of key1 and key2.
+func regtest(t *testing.T, n *node) (b *Bitcoin, addr1, addr2 string) {
+	t.Helper()
+
+	s := httptest.NewServer(n)
+	t.Cleanup(s.Close)
+
+	b, err := Init(config.BlockConfig{Name: RegTest, Node: s.URL, Secret: "user:pass", MaxBlocks: 6})
+	if err != nil {
+		t.Fatalf("Init err:%e", err)
+	}
+
+	if addr1, err = b.KeyAddress(key1); err != nil {
+		t.Fatalf("KeyAddress err:%e", err)
+	}
+
+	if addr2, err = b.KeyAddress(key2); err != nil {
+		t.Fatalf("KeyAddress err:%e", err)
+	}
+
+	return b, addr1, addr2
+}
+
+// TestDecode tests a block got with verbosity 3 is decoded with a transaction for each output paying to an address.
+func TestDecode(t *testing.T) {
+	n := &node{results: make(map[string]interface{})}
+	b, addr1, addr2 := regtest(t, n)
+
+	n.results["getblock"] = fmt.Sprintf(`{"hash":"0000000000000000000000000000000000000000000000000000000000000120",
+		"previousblockhash":"0000000000000000000000000000000000000000000000000000000000000119","height":120,
+		"time":1700000000,"tx":[
+		{"txid":"c0","vin":[{"coinbase":"0178"}],"vout":[{"value":50.00000000,"n":0,"scriptPubKey":{"address":"%s"}}]},
+		{"txid":"d1","fee":0.00001410,"vsize":141,"vin":[{"txid":"c0","vout":0,"prevout":{"value":1.00000000,
+		"scriptPubKey":{"address":"%s"}}}],"vout":[{"value":0.25000000,"n":0,"scriptPubKey":{"address":"%s"}},
+		{"value":0.74998590,"n":1,"scriptPubKey":{"address":"%s"}},{"value":0,"n":2,"scriptPubKey":{"type":"nulldata"}}]}
+		]}`, addr1, addr2, addr1, addr2)
+
+	var m map[string]interface{}
+	if err := b.GetBlock(120, true, &m); err != nil {
+		t.Fatalf("GetBlock err:%e", err)
+	}
+
+	blk, err := b.DecodeBlock(m)
+	if err != nil || blk.Number != "0x78" || blk.TS != "0x6553f100" ||
+		blk.PHash != "0000000000000000000000000000000000000000000000000000000000000119" {
+		t.Errorf("DecodeBlock error:%e Block:%+v", err, blk)
+	}
+
+	txs, err := b.DecodeTxs(m)
+	if err != nil || len(txs) != 3 {
+		t.Fatalf("DecodeTxs error:%e txs:%+v", err, txs)
+	}
+
+	for i, exp := range []types.Trans{
+		{Block: "0x78", Hash: "c0", To: addr1, Value: "0x12a05f200", Output: "0", Status: types.TxPending},
+		{Block: "0x78", Hash: "d1", From: addr2, To: addr1, Value: "0x17d7840", Output: "0", Price: 10, Fee: 1410,
+			Status: types.TxPending},
+		{Block: "0x78", Hash: "d1", From: addr2, To: addr2, Value: "0x478633e", Output: "1", Price: 10, Fee: 1410,
+			Status: types.TxPending},
+	} {
+		if !reflect.DeepEqual(txs[i], exp) {
+			t.Errorf("DecodeTxs tx %d:%+v expected:%+v", i, txs[i], exp)
+		}
+	}
+
+	if err = b.GetBlock(121, true, &m); !errors.Is(err, types.ErrNoBlock) {
+		t.Errorf("GetBlock not mined err:%e", err)
+	}
+}
+
+// TestSelectCoins tests the largest outputs are selected first and dust change is paid as fee.
+func TestSelectCoins(t *testing.T) {
+	utxos := func(values ...int64) (u []utxo) {
+		for _, v := range values {
+			u = append(u, utxo{value: v})
+		}
+
+		return u
+	}
+
+	for _, c := range []struct {
+		name             string
+		utxos            []utxo
+		value, rate      int64
+		sel, change, fee int64
+		err              error
+	}{
+		{"one", utxos(1000, 100000, 5000), 50000, 1, 1, 49859, 141, nil},
+		{"two", utxos(60000, 50000), 100000, 2, 2, 9582, 418, nil},
+		{"dust", utxos(50000), 49700, 1, 1, 0, 300, nil},
+		{"feeOnly", utxos(50000), 49900, 1, 0, 0, 0, ErrFunds},
+		{"funds", utxos(1000, 2000), 5000, 1, 0, 0, 0, ErrFunds},
+	} {
+		sel, change, fee, err := selectCoins(c.utxos, c.value, c.rate)
+		if len(sel) != int(c.sel) || change != c.change || fee != c.fee || !errors.Is(err, c.err) {
+			t.Errorf("%s: selected %d change %d fee %d err:%e", c.name, len(sel), change, fee, err)
+		}
+	}
+}
+
+// TestSend tests a transaction spending the outputs of an HD wallet address is signed, with the change sent to another
+// address, and the balance of the address is the sum of its outputs.
+func TestSend(t *testing.T) {
+	n := &node{results: make(map[string]interface{}), spent: make(map[string]bool)}
+	b, addr1, addr2 := regtest(t, n)
+
+	script, _ := b.script(addr1)
+	n.results["scantxoutset"] = fmt.Sprintf(`{"success":true,"unspents":[
+		{"txid":"%[1]s","vout":0,"scriptPubKey":"%[2]x","amount":0.00100000},
+		{"txid":"%[1]s","vout":1,"scriptPubKey":"%[2]x","amount":0.00050000},
+		{"txid":"%[1]s","vout":2,"scriptPubKey":"%[2]x","amount":0.00200000}],"total_amount":0.00350000}`,
+		"d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1", script)
+	n.results["estimatesmartfee"] = `{"errors":["Insufficient data or no feerate found"],"blocks":0}`
+
+	if bal, tok, err := b.Balance(addr1, ""); err != nil || bal.Int64() != 350000 || tok.Sign() != 0 {
+		t.Errorf("Balance:%s tokens:%s err:%e", bal, tok, err)
+	}
+
+	if _, _, err := b.Balance(addr1, addr2); !errors.Is(err, ErrToken) {
+		t.Errorf("Balance of token err:%e", err)
+	}
+
+	const to = "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"
+
+	fee, hash, err := b.SendChange(addr1, to, addr2, "0x3d090", key1, 0, false)
+	if err != nil || fee.Int64() != 209 {
+		t.Fatalf("SendChange fee:%s err:%e", fee, err)
+	}
+
+	raw, _ := hex.DecodeString(n.sent)
+	tx := wire.NewMsgTx(0)
+
+	if err = tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Sent transaction cannot be deserialized, err:%e", err)
+	}
+
+	toScript, _ := b.script(to)
+	changeScript, _ := b.script(addr2)
+
+	if hex.EncodeToString(hash) != tx.TxHash().String() || len(tx.TxIn) != 2 || len(tx.TxOut) != 2 ||
+		tx.TxOut[0].Value != 250000 || !bytes.Equal(tx.TxOut[0].PkScript, toScript) ||
+		tx.TxOut[1].Value != 49791 || !bytes.Equal(tx.TxOut[1].PkScript, changeScript) {
+		t.Errorf("Sent transaction %x:%+v", hash, tx)
+	}
+	// the signatures are valid
+	prev := txscript.NewMultiPrevOutFetcher(nil)
+	for i, in := range tx.TxIn {
+		prev.AddPrevOut(in.PreviousOutPoint, wire.NewTxOut([]int64{200000, 100000}[i], script))
+	}
+
+	for i, in := range tx.TxIn {
+		out := prev.FetchPrevOutput(in.PreviousOutPoint)
+
+		vm, err := txscript.NewEngine(out.PkScript, tx, i, txscript.StandardVerifyFlags, nil,
+			txscript.NewTxSigHashes(tx, prev), out.Value, prev)
+		if err == nil {
+			err = vm.Execute()
+		}
+
+		if err != nil {
+			t.Errorf("Input %d is not valid, err:%e", i, err)
+		}
+	}
+
+	// the outputs spent by the transaction in the mempool are not spent again
+	if _, _, err = b.SendChange(addr1, to, addr2, "100000", key1, 1, false); !errors.Is(err, ErrFunds) {
+		t.Errorf("SendChange spending the outputs in the mempool err:%e", err)
+	}
+
+	if _, _, err = b.SendChange(addr1, to, addr2, "40000", key1, 1, false); err != nil {
+		t.Errorf("SendChange err:%e", err)
+	} else if tx.Deserialize(hex.NewDecoder(strings.NewReader(n.sent))) != nil || len(tx.TxIn) != 1 ||
+		tx.TxIn[0].PreviousOutPoint.Index != 1 {
+		t.Errorf("Sent transaction spending the output not spent:%+v", tx)
+	}
+
+	for _, c := range []struct {
+		name, from, amount, key string
+		err                     error
+	}{
+		{"key", addr2, "250000", key1, ErrKey},
+		{"badKey", addr1, "250000", "0x01", ErrKey},
+		{"amount", addr1, "one", key1, ErrAmount},
+		{"dust", addr1, "200", key1, ErrDust},
+		{"funds", addr1, "40000", key1, ErrFunds},
+	} {
+		if _, _, err = b.SendChange(c.from, to, addr2, c.amount, c.key, 1, true); !errors.Is(err, c.err) {
+			t.Errorf("%s: SendChange err:%e", c.name, err)
+		}
+	}
+
+	if _, _, err = b.Send(addr1, to, addr2, "250000", nil, key1, &types.Fees{Price: 1}, true); !errors.Is(err, ErrToken) {
+		t.Errorf("Send token err:%e", err)
+	}
+}
+
+// TestGet tests a transaction mined is got with its block number in hexadecimal, as decoded from the blocks.
+func TestGet(t *testing.T) {
+	n := &node{results: make(map[string]interface{})}
+	b, addr1, _ := regtest(t, n)
+
+	n.results["getrawtransaction"] = fmt.Sprintf(`{"txid":"d1","blockhash":"b120","confirmations":1,
+		"blocktime":1700000000,"vout":[{"value":0.25000000,"n":0,"scriptPubKey":{"address":"%s"}}]}`, addr1)
+	n.results["getblockheader"] = `{"height":120}`
+
+	tx, err := b.Get("d1")
+	if err != nil || tx.Block != "0x78" || tx.Status != types.TxSuccess || tx.To != addr1 || tx.Value != "0x17d7840" {
+		t.Errorf("Get tx:%+v err:%e", tx, err)
+	}
+}
+
+// TestAddress tests the validation of the addresses of the network.
+func TestAddress(t *testing.T) {
+	b, addr1, _ := regtest(t, &node{})
+
+	for _, c := range []struct {
+		addr, canonical string
+		err             error
+	}{
+		{addr1, addr1, nil},
+		{strings.ToUpper(addr1), addr1, nil},
+		{"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", nil},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "", types.ErrAddress}, // main net
+		{"0x7762440182222620a7435195208038708d27ee41", "", types.ErrAddress},
+		{addr1[:len(addr1)-1] + "q", "", types.ErrAddress}, // wrong checksum
+	} {
+		if a, err := b.Address(c.addr); a != c.canonical || !errors.Is(err, c.err) {
+			t.Errorf("Address(%s) returned %s err:%e", c.addr, a, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// rpcTimeout is the maximum time to wait for a reply of the node.
+const rpcTimeout = time.Minute
+
+// Bitcoin Core RPC error codes.
+const (
+	errInvalidAddressOrKey = -5 // ie. transaction not found
+	errInvalidParameter    = -8 // ie. block height out of range
+)
+
+// satoshis is the number of satoshis in a bitcoin.
+const satoshis = 100000000
+
+// rpcError is an error replied by the node.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("bitcoin: rpc error %d: %s", e.Code, e.Message)
+}
+
+// code returns the RPC error code of err, 0 if it is not an error replied by the node.
+func code(err error) int {
+	var e *rpcError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return 0
+}
+
+// client is a JSON-RPC client of a Bitcoin Core node.
+type client struct {
+	url  string
+	user string
+	pass string
+	id   uint64
+	hc   *http.Client
+}
+
+// newClient returns a client of the node at url 'node', 'secret' is the RPC user and password (user:password) if the
+// node requires authentication.
+func newClient(node, secret string) *client {
+	c := &client{url: node, hc: &http.Client{Timeout: rpcTimeout}}
+	c.user, c.pass, _ = strings.Cut(secret, ":")
+
+	return c
+}
+
+// call calls the RPC 'method' with the given params, decoding its result onto 'result' if not nil. Numbers are
+// decoded as json.Number so amounts do not lose precision.
+func (c *client) call(method string, params []interface{}, result interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0", "id": atomic.AddUint64(&c.id, 1), "method": method, "params": params,
+	})
+	if err != nil {
+		return fmt.Errorf("bitcoin: cannot encode %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("bitcoin: cannot create %s request: %w", method, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+
+	res, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("bitcoin: cannot call %s: %w", method, err)
+	}
+	defer res.Body.Close()
+	// the node replies errors with an HTTP error status and the error in the body
+	var r struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return fmt.Errorf("bitcoin: cannot decode %s reply (%s): %w", method, res.Status, err)
+	}
+
+	if r.Error != nil {
+		return r.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(r.Result))
+	d.UseNumber()
+
+	if err = d.Decode(result); err != nil {
+		return fmt.Errorf("bitcoin: cannot decode %s result: %w", method, err)
+	}
+
+	return nil
+}
+
+// sats converts an amount in bitcoins, as decoded from the replies of the node, to satoshis.
+func sats(v interface{}) (int64, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, ErrAmount
+	}
+
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrAmount, n)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(satoshis))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("%w: %s", ErrAmount, n)
+	}
+
+	return r.Num().Int64(), nil
+}
@@ -2,9 +2,15 @@
 package block
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"math/big"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/tarancss/adp/lib/block/bitcoin"
 	"github.com/tarancss/adp/lib/block/ethereum"
 	"github.com/tarancss/adp/lib/block/types"
 	"github.com/tarancss/adp/lib/config"
@@ -14,42 +20,168 @@ import (
 // however, there may be specific blockchains or networks that would require different types or more methods.
 type Chain interface {
 	// member-type methods
-	MaxBlocks() int // number of blocks that are controlled for orphans (uncles)
-	AvgBlock() int  // average block mining rate in seconds
+	MaxBlocks() int                  // number of blocks that are controlled for orphans (uncles)
+	AvgBlock() (time.Duration, bool) // average block mining rate, and if it is configured or has to be estimated
+	Confirmations() int              // number of blocks mined on top of a block to confirm its transactions
+	Rate() int                       // maximum number of blocks requested per second
+	Window() int                     // number of blocks requested concurrently
+	StartBlock() string              // first block explored for a new network (see config.BlockConfig)
+	Native() types.Token             // native currency of the network (ie. ether)
 	// methods
 	Close()
 	Balance(account, token string) (bal, tokBal *big.Int, err error)
+	Latest() (uint64, error)
 	GetBlock(block uint64, full bool, response interface{}) error
 	DecodeBlock(b interface{}) (types.Block, error)
 	DecodeTxs(t interface{}) ([]types.Trans, error)
+	Transfers(b types.Block) ([]types.Trans, error)
 	GetToken(token string) (types.Token, error)
-	Send(fromAddress, toAddress, token, amount string, data []byte, key string, priceIn uint64,
-		dryRun bool) (fee *big.Int, hash []byte, err error)
+	Send(fromAddress, toAddress, token, amount string, data []byte, key string, fees *types.Fees,
+		dryRun bool) (fee *big.Int, hash []byte, err error) // fees are loaded with the ones offered
 	Get(hash string) (t *types.Trans, err error)
+	Receipt(t *types.Trans) error
+	Address(addr string) (string, error) // validates an address returning its canonical form (stored and compared)
+	Checksum(addr string) string         // returns the form of a canonical address shown to clients
 }
 
+// UTXO is implemented by the chains whose transactions spend unspent transaction outputs (ie. bitcoin). Their addresses
+// are derived from the public key of the HD wallet keys, and the change of the outputs spent is sent to an address of
+// the HD wallet's change branch.
+type UTXO interface {
+	Chain
+	KeyAddress(key string) (string, error) // address of a private key (hexadecimal)
+	SendChange(from, to, change, amount, key string, feeRate uint64, dryRun bool) (fee *big.Int, hash []byte, err error)
+}
+
+// Account is implemented by the chains whose transactions sent from an address are ordered by a nonce (ie. ethereum),
+// so the nonces of the transactions sent can be allocated by the caller instead of asking the node.
+type Account interface {
+	Chain
+	Nonce(addr string) (uint64, error) // nonce of the next transaction of an address, counting the pending ones
+	SendNonce(fromAddress, toAddress, token, amount string, data []byte, key string, fees *types.Fees, nonce uint64,
+		dryRun bool) (fee *big.Int, hash []byte, err error) // returns types.ErrNonce if the nonce has been used
+}
+
+// Errors returned validating the blockchain configuration.
+var (
+	ErrConfirmations = errors.New("confirmations have to be lower than maxBlocks")
+	ErrStartBlock    = errors.New(`start block has to be a block number, "latest" or "latest-N"`)
+	ErrNoInterface   = errors.New("blockchain interface not defined")
+)
+
 // Init loads all the clients read from the config to blockchains into a map.
 func Init(bc []config.BlockConfig) (m map[string]Chain, err error) {
 	m = make(map[string]Chain)
 
 	for _, block := range bc {
-		// connect
-		var tmp interface{}
+		var c Chain
+
+		if c, err = New(block); err != nil {
+			if errors.Is(err, ErrNoInterface) {
+				log.Printf("Blockchain interface not defined for %s. Ignoring...\n", block.Name)
+
+				err = nil
 
-		if block.Name == "ropsten" || block.Name == "rinkeby" || block.Name == "mainNet" {
-			if tmp, err = ethereum.Init(block.Node, block.Secret, block.MaxBlocks); err != nil {
-				return
+				continue
 			}
 
-			m[block.Name] = tmp.(Chain)
-		} else {
-			log.Printf("Blockchain interface not defined for %s. Ignoring...\n", block.Name)
+			return
 		}
+
+		m[block.Name] = c
 	}
 
 	return
 }
 
+// New validates the configuration of a blockchain and returns its client for its chain type.
+func New(block config.BlockConfig) (Chain, error) {
+	if block.Confirmations < 0 || block.Confirmations >= block.MaxBlocks {
+		return nil, fmt.Errorf("%w: %s", ErrConfirmations, block.Name)
+	}
+
+	if _, _, err := parseStart(block.StartBlock); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, block.Name)
+	}
+	// connect
+	switch chainType(block) {
+	case config.TypeEVM:
+		tmp, err := ethereum.Init(block)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to %s: %w", block.Name, err)
+		}
+
+		return tmp, nil
+	case config.TypeBitcoin:
+		tmp, err := bitcoin.Init(block)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to %s: %w", block.Name, err)
+		}
+
+		return tmp, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoInterface, block.Name)
+}
+
+// chainType returns the chain type of a network, inferred from the name of the networks known if not configured.
+func chainType(block config.BlockConfig) string {
+	if block.Type != "" {
+		return block.Type
+	}
+
+	switch block.Name {
+	case "ropsten", "rinkeby", "mainNet":
+		return config.TypeEVM
+	case bitcoin.MainNet, bitcoin.TestNet, bitcoin.RegTest:
+		return config.TypeBitcoin
+	}
+
+	return ""
+}
+
+// Start returns the first block to be explored for the blockchain 'c' according to its start block configuration,
+// asking the node for its latest block if required.
+func Start(c Chain) (uint64, error) {
+	n, latest, err := parseStart(c.StartBlock())
+	if err != nil || !latest {
+		return n, err
+	}
+
+	head, err := c.Latest()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get latest block: %w", err)
+	}
+
+	if n > head {
+		return 0, nil
+	}
+
+	return head - n, nil
+}
+
+// parseStart decodes a start block returning the block number or, if 'latest' is true, the number of blocks before the
+// latest block.
+func parseStart(start string) (n uint64, latest bool, err error) {
+	switch {
+	case start == "":
+		return 0, false, nil
+	case start == "latest":
+		return 0, true, nil
+	case strings.HasPrefix(start, "latest-"):
+		latest = true
+		n, err = strconv.ParseUint(strings.TrimPrefix(start, "latest-"), 10, 64)
+	default:
+		n, err = strconv.ParseUint(start, 10, 64)
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %s", ErrStartBlock, start)
+	}
+
+	return n, latest, nil
+}
+
 // End closes gracefully all the blockchain clients opened.
 func End(bc map[string]Chain) {
 	for _, block := range bc {
@@ -0,0 +1,60 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tarancss/adp/lib/block/bitcoin"
+	"github.com/tarancss/adp/lib/config"
+)
+
+// TestParseStart tests the decoding of the start block configuration.
+func TestParseStart(t *testing.T) {
+	cases := []struct {
+		start  string
+		n      uint64
+		latest bool
+		err    error
+	}{
+		{"", 0, false, nil},
+		{"1200", 1200, false, nil},
+		{"latest", 0, true, nil},
+		{"latest-64", 64, true, nil},
+		{"latest-", 0, false, ErrStartBlock},
+		{"earliest", 0, false, ErrStartBlock},
+		{"-5", 0, false, ErrStartBlock},
+	}
+
+	for _, c := range cases {
+		n, latest, err := parseStart(c.start)
+		if n != c.n || latest != c.latest || !errors.Is(err, c.err) {
+			t.Errorf("parseStart(%s) returned %d %t err:%e", c.start, n, latest, err)
+		}
+	}
+}
+
+// TestNew tests networks are created by their chain type, inferred from the name of the networks known if not set.
+func TestNew(t *testing.T) {
+	cases := []struct {
+		conf   config.BlockConfig
+		symbol string
+		err    error
+	}{
+		{config.BlockConfig{Name: "ropsten"}, "ETH", nil},
+		{config.BlockConfig{Name: "sepolia"}, "", ErrNoInterface},
+		{config.BlockConfig{Name: "sepolia", Type: config.TypeEVM, ChainID: 11155111}, "ETH", nil},
+		{config.BlockConfig{Name: "polygon", Type: config.TypeEVM, Symbol: "POL"}, "POL", nil},
+		{config.BlockConfig{Name: "solana", Type: "svm"}, "", ErrNoInterface},
+		{config.BlockConfig{Name: bitcoin.RegTest}, "BTC", nil},
+		{config.BlockConfig{Name: "litecoin", Type: config.TypeBitcoin}, "", bitcoin.ErrNetwork},
+	}
+
+	for _, c := range cases {
+		c.conf.Node, c.conf.MaxBlocks = "http://localhost:8545", 6
+
+		b, err := New(c.conf)
+		if !errors.Is(err, c.err) || (err == nil && b.Native().Symbol != c.symbol) {
+			t.Errorf("New(%s) returned %v err:%e", c.conf.Name, b, err)
+		}
+	}
+}
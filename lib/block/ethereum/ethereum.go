@@ -2,41 +2,124 @@
 package ethereum
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/big"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
 	"github.com/tarancss/ethcli"
+	"golang.org/x/crypto/sha3"
+)
+
+// Tracing modes to detect internal ether transfers, see config.BlockConfig.
+const (
+	TraceDebug  = "debug"  // debug_traceBlockByNumber with the callTracer (geth)
+	TraceParity = "parity" // trace_block (openethereum, erigon, nethermind)
+)
+
+// Errors returned by the ethereum interface.
+var (
+	ErrTrace   = errors.New(`trace has to be "debug", "parity" or empty`)
+	ErrAmount  = errors.New("ethereum: invalid amount")
+	ErrKey     = errors.New("ethereum: invalid key for the address")
+	ErrChainID = errors.New("ethereum: invalid chain id")
 )
 
 // Ethereum implements a connection to an ethereum-type chain.
 type Ethereum struct {
-	c  *ethcli.EthCli
-	mb int
+	c    *ethcli.EthCli
+	rpc  caller // JSON-RPC calls to the node, the client c unless testing
+	conf config.BlockConfig
+}
+
+// caller makes JSON-RPC calls to a node, loading their result onto 'result'.
+type caller interface {
+	Call(method string, params, result interface{}) error
 }
 
-// Init returns a connection to an ethereum node, using secret if necessary for authentication. maxBlocks is required
-// to indicate how many blocks will be taken into account for uncle management.
-func Init(node, secret string, maxBlocks int) (*Ethereum, error) {
-	c := ethcli.Init(node, secret)
+// Init returns a connection to an ethereum node given in the blockchain configuration, using its secret if necessary
+// for authentication. MaxBlocks is required to indicate how many blocks will be taken into account for uncle
+// management.
+func Init(conf config.BlockConfig) (*Ethereum, error) {
+	if conf.Trace != "" && conf.Trace != TraceDebug && conf.Trace != TraceParity {
+		return nil, fmt.Errorf("%w: %s", ErrTrace, conf.Trace)
+	}
+
+	c := ethcli.Init(conf.Node, conf.Secret)
 	if c == nil {
-		return nil, errors.New("cannot connect to ethereum blockchain in" + node)
+		return nil, errors.New("cannot connect to ethereum blockchain in" + conf.Node)
 	}
 
-	return &Ethereum{c: c, mb: maxBlocks}, nil
+	return &Ethereum{c: c, rpc: c, conf: conf}, nil
 }
 
 // MaxBlocks returns how many blocks will be taken into account for uncle management.
 func (e *Ethereum) MaxBlocks() int {
-	return e.mb
+	return e.conf.MaxBlocks
+}
+
+// Confirmations returns how many blocks have to be mined on top of a block for its transactions to be confirmed.
+func (e *Ethereum) Confirmations() int {
+	return e.conf.Confirmations
+}
+
+// Rate returns the maximum number of blocks requested per second to the node, 1 by default.
+func (e *Ethereum) Rate() int {
+	if e.conf.Rate < 1 {
+		return 1
+	}
+
+	return e.conf.Rate
+}
+
+// Window returns the number of blocks that can be requested concurrently to the node, 1 by default.
+func (e *Ethereum) Window() int {
+	if e.conf.Window < 1 {
+		return 1
+	}
+
+	return e.conf.Window
+}
+
+// StartBlock returns the first block to be explored for a new network.
+func (e *Ethereum) StartBlock() string {
+	return e.conf.StartBlock
 }
 
-// AvgBlock returns the average time to mine a block in seconds.
-func (e *Ethereum) AvgBlock() int {
-	return 15 //nolint:gomnd // we could put this in the config file...
+// Native returns the native currency of the network, ETH with 18 decimals unless configured.
+func (e *Ethereum) Native() types.Token {
+	t := types.Token{Name: e.conf.Name, Symbol: e.conf.Symbol, Decimals: e.conf.Decimals}
+	if t.Symbol == "" {
+		t.Symbol = "ETH"
+	}
+
+	if t.Decimals == 0 {
+		t.Decimals = 18
+	}
+
+	return t
+}
+
+// AvgBlock returns the average time to mine a block configured, or 12 seconds and false if it is not configured and
+// has to be estimated.
+func (e *Ethereum) AvgBlock() (time.Duration, bool) {
+	if e.conf.BlockTime > 0 {
+		return time.Duration(e.conf.BlockTime * float64(time.Second)), true
+	}
+
+	return 12 * time.Second, false //nolint:gomnd // default block time
 }
 
 // Close ends a connection.
@@ -53,6 +136,16 @@ func (e *Ethereum) Balance(address, token string) (ethBal, tokBal *big.Int, err
 	return e.c.GetBalance(address, token)
 }
 
+// Latest returns the number of the latest block mined.
+func (e *Ethereum) Latest() (uint64, error) {
+	n, err := e.c.GetLatestBlock()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get latest block: %w", err)
+	}
+
+	return n, nil
+}
+
 // GetBlock returns in response the block number requested. If full, it provides all the details of the transactions.
 func (e *Ethereum) GetBlock(block uint64, full bool, response interface{}) (err error) {
 	if err = e.c.GetBlockByNumber(block, full, response.(*map[string]interface{})); errors.Is(err, ethcli.ErrNoBlock) {
@@ -135,9 +228,6 @@ func (e *Ethereum) DecodeTxs(t interface{}) (txs []types.Trans, err error) {
 					return
 				}
 
-				if txs[i].To, ok = txObj["to"].(string); !ok {
-					continue // contract creation, so we dont care about this transaction's details
-				}
 				// input
 				var tmp string
 
@@ -147,78 +237,25 @@ func (e *Ethereum) DecodeTxs(t interface{}) (txs []types.Trans, err error) {
 					return
 				}
 
-				if tmp == "0x" || (len(tmp) > 2 && len(tmp) <= 10) ||
-					(len(tmp) > 10 &&
-						tmp[2:10] != ethcli.ERC20transfer &&
-						tmp[2:10] != ethcli.ERC20transfer256 &&
-						tmp[2:10] != ethcli.ERC20transferFrom &&
-						tmp[2:10] != ethcli.ERC20transferFrom256) {
-					// this is an ether transfer
-					if txs[i].Value, ok = txObj["value"].(string); !ok {
-						err = types.ErrNoTrxValue
-
-						return
-					}
-
-					if txs[i].From, ok = txObj["from"].(string); !ok {
-						err = types.ErrNoTrxFrom
-
-						return
-					}
-					// To is already done
-					txs[i].Data = tmp
-				} else {
-					// this is a token transaction
-					if len(tmp) > 10 {
-						if tmp[2:10] == ethcli.ERC20transfer || tmp[2:10] == ethcli.ERC20transfer256 {
-							if len(tmp) >= 138 {
-								if txs[i].From, ok = txObj["from"].(string); !ok {
-									err = types.ErrNoTrxFrom
-
-									return
-								}
-								// To comes in "input" after 24 padded 0s
-								txs[i].To = "0x" + tmp[10+24:74]
-
-								// Value, trimming left zeroes
-								var j int
-								for j = 74; j < 138 && tmp[j] == '0'; j++ {
-								}
-								if j%2 == 1 {
-									j-- // keep Value with even hex-digits
-								}
-								txs[i].Value = "0x" + tmp[j:138]
-							} else {
-								err = types.ErrTrxWrongLen
-
-								return
-							}
-						} else if tmp[2:10] == ethcli.ERC20transferFrom || tmp[2:10] == ethcli.ERC20transferFrom256 {
-							if len(tmp) >= 202 {
-								// From comes in "input" after 24 padded 0s, then To after 24 padded 0s
-								txs[i].From = "0x" + tmp[10+24:74]
-								txs[i].To = "0x" + tmp[74+24:138]
-								// Value, trimming left zeroes
-								var j int
-								for j = 138; j < 202 && tmp[j] == '0'; j++ {
-								}
-								if j%2 == 1 {
-									j--
-								}
-								txs[i].Value = "0x" + tmp[j:202]
-							} else {
-								err = types.ErrTrxWrongLen
-
-								return
-							}
-						}
-						// Token it's the smart contract address that comes in "to"
-						txs[i].Token, _ = txObj["to"].(string)
-						// Data is empty
-					} else {
-						log.Printf("WARN: very short \"input\" field in block's transaction...%s\n", tmp)
-					}
+				if txs[i].To, ok = txObj["to"].(string); !ok {
+					// contract creation, the contract address is got by Receipt
+					txs[i].Kind = types.KindCreate
 				}
+				// token transfers are got from the block's logs by Transfers, so any other transaction is decoded as an
+				// ether transfer
+				if txs[i].Value, ok = txObj["value"].(string); !ok {
+					err = types.ErrNoTrxValue
+
+					return
+				}
+
+				if txs[i].From, ok = txObj["from"].(string); !ok {
+					err = types.ErrNoTrxFrom
+
+					return
+				}
+
+				txs[i].Data = tmp
 
 				if txs[i].Gas, ok = txObj["gas"].(string); !ok {
 					err = types.ErrNoTrxGasUsed
@@ -235,10 +272,9 @@ func (e *Ethereum) DecodeTxs(t interface{}) (txs []types.Trans, err error) {
 				if txs[i].Price, err = strconv.ParseUint(tmp, 0, 64); err != nil {
 					return
 				}
-				// Token has to be parsed from Data
 				// timestamp should be got from block's ts
-				// fee is gas*price but gas here is the one sent, not consumed!!
-				txs[i].Status = ethcli.TrxPending // status should be got from TransactionReceipt
+				// gas here is the one sent, not consumed, so status, gas used and fee are got by Receipt
+				txs[i].Status = ethcli.TrxPending
 			}
 		default:
 			log.Printf("NODE ERROR: unknown txList type %T\n", t)
@@ -248,6 +284,257 @@ func (e *Ethereum) DecodeTxs(t interface{}) (txs []types.Trans, err error) {
 	return
 }
 
+// TransferTopic is the topic of the ERC20 Transfer(address,address,uint256) event.
+const TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// Transfers returns the ERC20 token transfers of the block, one for each Transfer log of the block, so the tokens
+// transferred by any contract (routers, multisigs, ...) are detected. If tracing is enabled, the internal ether
+// transfers made by contracts are also returned. It is used after a call to DecodeBlock and DecodeTxs.
+func (e *Ethereum) Transfers(blk types.Block) ([]types.Trans, error) {
+	var logs []interface{}
+
+	filter := map[string]interface{}{"blockHash": blk.Hash, "topics": []string{TransferTopic}}
+	if err := e.c.Call("eth_getLogs", []interface{}{filter}, &logs); err != nil {
+		return nil, fmt.Errorf("cannot get logs of block %s: %w", blk.Hash, err)
+	}
+
+	txs, err := decodeTransfers(logs)
+	if err != nil {
+		return nil, err
+	}
+
+	var traces []interface{}
+
+	switch e.conf.Trace {
+	case TraceDebug:
+		tracer := map[string]string{"tracer": "callTracer"}
+		if err = e.c.Call("debug_traceBlockByNumber", []interface{}{blk.Number, tracer}, &traces); err != nil {
+			return nil, fmt.Errorf("cannot trace block %s: %w", blk.Number, err)
+		}
+
+		return decodeCallTraces(txs, blk, traces)
+	case TraceParity:
+		if err = e.c.Call("trace_block", []interface{}{blk.Number}, &traces); err != nil {
+			return nil, fmt.Errorf("cannot trace block %s: %w", blk.Number, err)
+		}
+
+		return decodeParityTraces(txs, blk, traces)
+	}
+
+	return txs, nil
+}
+
+// decodeCallTraces appends to txs a transaction of KindInternal for each internal call that transfers ether. The
+// traces are the result of debug_traceBlockByNumber with the callTracer, one for each transaction of the block.
+// Reverted calls and their subcalls are ignored as they do not transfer any ether.
+func decodeCallTraces(txs []types.Trans, blk types.Block, traces []interface{}) ([]types.Trans, error) {
+	for i, t := range traces {
+		m, ok := t.(map[string]interface{})
+		if !ok {
+			return nil, types.ErrTraceDecode
+		}
+		// old nodes do not inform the transaction hash, but the traces are in the order of the block's transactions
+		hash, _ := m["txHash"].(string)
+		if hash == "" && i < len(blk.Tx) {
+			hash = blk.Tx[i].Hash
+		}
+
+		frame, ok := m["result"].(map[string]interface{})
+		if !ok || hash == "" {
+			return nil, types.ErrTraceDecode
+		}
+
+		if _, failed := frame["error"]; failed {
+			continue
+		}
+
+		calls, _ := frame["calls"].([]interface{})
+		txs = appendCalls(txs, blk.Number, hash, "", calls)
+	}
+
+	return txs, nil
+}
+
+// appendCalls appends the calls that transfer ether, and their subcalls recursively, of a callTracer frame. 'trace' is
+// the trace address of the frame, empty for the transaction's frame.
+func appendCalls(txs []types.Trans, block, hash, trace string, calls []interface{}) []types.Trans {
+	for i, c := range calls {
+		f, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if _, failed := f["error"]; failed {
+			continue
+		}
+
+		addr := strconv.Itoa(i)
+		if trace != "" {
+			addr = trace + "-" + addr
+		}
+		// delegate and static calls do not transfer ether
+		typ, _ := f["type"].(string)
+		value, _ := f["value"].(string)
+
+		if typ != "DELEGATECALL" && typ != "STATICCALL" && !zero(value) {
+			tx := types.Trans{Block: block, Hash: hash, Value: value, Kind: types.KindInternal, Trace: addr}
+			tx.From, _ = f["from"].(string)
+			tx.To, _ = f["to"].(string)
+			txs = append(txs, tx)
+		}
+
+		sub, _ := f["calls"].([]interface{})
+		txs = appendCalls(txs, block, hash, addr, sub)
+	}
+
+	return txs
+}
+
+// decodeParityTraces appends to txs a transaction of KindInternal for each internal call, contract creation or self
+// destruct that transfers ether. The traces are the result of trace_block, where the transactions' traces have an
+// empty trace address. Failed traces and their subtraces are ignored as they do not transfer any ether.
+func decodeParityTraces(txs []types.Trans, blk types.Block, traces []interface{}) ([]types.Trans, error) {
+	failed := make(map[string]bool) // trace addresses of failed traces by transaction hash
+
+	for _, t := range traces {
+		m, ok := t.(map[string]interface{})
+		if !ok {
+			return nil, types.ErrTraceDecode
+		}
+
+		hash, _ := m["transactionHash"].(string)
+		action, _ := m["action"].(map[string]interface{})
+		ta, _ := m["traceAddress"].([]interface{})
+
+		addr := make([]string, len(ta))
+		for i := range ta {
+			n, ok := ta[i].(float64)
+			if !ok {
+				return nil, types.ErrTraceDecode
+			}
+
+			addr[i] = strconv.Itoa(int(n))
+		}
+		// a trace fails if any of its parents failed
+		var parentFailed bool
+		for i := 0; i <= len(addr) && !parentFailed; i++ {
+			parentFailed = failed[hash+"/"+strings.Join(addr[:i], "-")]
+		}
+
+		if _, ok := m["error"]; ok || parentFailed {
+			failed[hash+"/"+strings.Join(addr, "-")] = true
+
+			continue
+		}
+
+		if len(addr) == 0 || hash == "" || action == nil {
+			continue // the transaction itself, or a block reward
+		}
+
+		tx := types.Trans{Block: blk.Number, Hash: hash, Kind: types.KindInternal, Trace: strings.Join(addr, "-")}
+
+		switch m["type"] {
+		case "call":
+			if ct, _ := action["callType"].(string); ct == "delegatecall" || ct == "staticcall" {
+				continue
+			}
+
+			tx.From, _ = action["from"].(string)
+			tx.To, _ = action["to"].(string)
+			tx.Value, _ = action["value"].(string)
+		case "create":
+			result, _ := m["result"].(map[string]interface{})
+			tx.From, _ = action["from"].(string)
+			tx.To, _ = result["address"].(string)
+			tx.Value, _ = action["value"].(string)
+		case "suicide":
+			tx.From, _ = action["address"].(string)
+			tx.To, _ = action["refundAddress"].(string)
+			tx.Value, _ = action["balance"].(string)
+		}
+
+		if !zero(tx.Value) {
+			txs = append(txs, tx)
+		}
+	}
+
+	return txs, nil
+}
+
+// zero checks if a hex value is zero or empty.
+func zero(value string) bool {
+	return strings.TrimLeft(strings.TrimPrefix(value, "0x"), "0") == ""
+}
+
+// decodeTransfers returns a transaction of KindToken for each ERC20 Transfer log. Logs of other events, removed logs
+// and ERC721 Transfer logs (with the token id indexed) are ignored. It is used after a call to eth_getLogs.
+func decodeTransfers(logs []interface{}) (txs []types.Trans, err error) {
+	for _, l := range logs {
+		m, ok := l.(map[string]interface{})
+		if !ok {
+			return nil, types.ErrLogDecode
+		}
+
+		if removed, _ := m["removed"].(bool); removed {
+			continue
+		}
+
+		topics, _ := m["topics"].([]interface{})
+		data, _ := m["data"].(string)
+
+		if len(topics) != 3 || topics[0] != TransferTopic || len(data) != 66 {
+			continue
+		}
+
+		tx := types.Trans{Kind: types.KindToken}
+
+		var from, to string
+
+		if from, ok = topics[1].(string); !ok || len(from) != 66 {
+			return nil, types.ErrLogDecode
+		}
+
+		if to, ok = topics[2].(string); !ok || len(to) != 66 {
+			return nil, types.ErrLogDecode
+		}
+		// addresses come in topics after 24 padded 0s
+		tx.From, tx.To = "0x"+from[2+24:], "0x"+to[2+24:]
+
+		if tx.Block, ok = m["blockNumber"].(string); !ok {
+			return nil, types.ErrNoBlockNumber
+		}
+
+		if tx.Hash, ok = m["transactionHash"].(string); !ok {
+			return nil, types.ErrNoTrxHash
+		}
+
+		if tx.Token, ok = m["address"].(string); !ok {
+			return nil, types.ErrLogDecode
+		}
+
+		if tx.LogIndex, ok = m["logIndex"].(string); !ok {
+			return nil, types.ErrLogDecode
+		}
+		// Value, trimming left zeroes
+		var j int
+		for j = 2; j < 66 && data[j] == '0'; j++ {
+		}
+
+		if j%2 == 1 {
+			j-- // keep Value with even hex-digits
+		}
+
+		tx.Value = "0x" + data[j:]
+		if tx.Value == "0x" {
+			tx.Value = "0x0"
+		}
+
+		txs = append(txs, tx)
+	}
+
+	return txs, nil
+}
+
 // GetToken returns the name, symbol and decimals of a valid ERC20 token.
 func (e *Ethereum) GetToken(token string) (t types.Token, err error) {
 	if t.Name, err = e.c.GetTokenName(token); err != nil {
@@ -272,25 +559,361 @@ func (e *Ethereum) GetToken(token string) (t types.Token, err error) {
 
 // Send executes a transaction in the blockchain with the given parameters returning the expected fee, the transaction
 // hash or an error otherwise. If 'dryRun' is true, the transaction will not be sent to the blockchain but still a
-// valid hash will be returned.
-func (e *Ethereum) Send(fromAddress, toAddress, token, amount string, data []byte, key string, priceIn uint64,
+// valid hash will be returned. The transaction is signed for the chain id of the network (EIP-155), so it cannot be
+// replayed in other networks. If a token is given, an ERC20 transfer of the amount is sent to the token contract.
+// An EIP-1559 dynamic fee transaction is sent unless a gas price is given or the network does not support it, with
+// the fees not given estimated and loaded onto 'fees' (see Fees). The fee returned is the maximum fee of the
+// transaction, the effective fee is known once mined (see Receipt). The nonce of the transaction is the one informed
+// by the node for the sender, including its pending transactions (see SendNonce).
+func (e *Ethereum) Send(fromAddress, toAddress, token, amount string, data []byte, key string, fees *types.Fees,
 	dryRun bool) (fee *big.Int, hash []byte, err error) {
-	var price, gas uint64
-	price, gas, hash, err = e.c.SendTrx(fromAddress, toAddress, token, amount, data, key, priceIn, dryRun)
-	fee = new(big.Int).SetUint64(price)
+	nonce, err := e.Nonce(fromAddress)
+	if err != nil {
+		return new(big.Int), nil, err
+	}
 
-	var gB *big.Int = new(big.Int).SetUint64(gas)
-	fee = fee.Mul(fee, gB)
+	return e.SendNonce(fromAddress, toAddress, token, amount, data, key, fees, nonce, dryRun)
+}
 
-	return
+// Nonce returns the nonce of the next transaction sent from an address, counting its pending transactions.
+func (e *Ethereum) Nonce(addr string) (uint64, error) {
+	return e.quantity("eth_getTransactionCount", addr, "pending")
+}
+
+// SendNonce sends a transaction like Send with the nonce given. If the node rejects the transaction because the
+// nonce has already been used, types.ErrNonce is returned.
+func (e *Ethereum) SendNonce(fromAddress, toAddress, token, amount string, data []byte, key string, fees *types.Fees,
+	nonce uint64, dryRun bool) (fee *big.Int, hash []byte, err error) {
+	fee = new(big.Int)
+
+	if token != "" && data != nil {
+		return fee, nil, types.ErrSendTokenData
+	}
+
+	prv, err := crypto.HexToECDSA(key)
+	if err != nil {
+		return fee, nil, fmt.Errorf("%w: %v", ErrKey, err)
+	}
+
+	if !strings.EqualFold(crypto.PubkeyToAddress(prv.PublicKey).Hex(), fromAddress) {
+		return fee, nil, fmt.Errorf("%w: %s", ErrKey, fromAddress)
+	}
+
+	value, ok := new(big.Int).SetString(amount, 0)
+	if !ok || value.Sign() < 0 || value.BitLen() > 256 { //nolint:gomnd // uint256
+		return fee, nil, fmt.Errorf("%w: %s", ErrAmount, amount)
+	}
+
+	var to *common.Address // nil for a contract creation
+
+	if toAddress != "" {
+		addr := common.HexToAddress(toAddress)
+		to = &addr
+	}
+
+	if token != "" {
+		if to == nil {
+			return fee, nil, fmt.Errorf("%w: a token transfer needs a recipient", types.ErrAddress)
+		}
+		// transfer(address,uint256) to the token contract without ether
+		data = append([]byte{0xa9, 0x05, 0x9c, 0xbb}, common.LeftPadBytes(to.Bytes(), 32)...) //nolint:gomnd // word
+		data = append(data, common.LeftPadBytes(value.Bytes(), 32)...)                        //nolint:gomnd // word
+		addr := common.HexToAddress(token)
+		to, value = &addr, new(big.Int)
+	}
+
+	chainID, err := e.chainID()
+	if err != nil {
+		return fee, nil, err
+	}
+
+	if err = e.fees(fees); err != nil {
+		return fee, nil, err
+	}
+
+	gas, err := e.estimateGas(fromAddress, to, value, data)
+	if err != nil {
+		return fee, nil, err
+	}
+
+	var txData ethtypes.TxData
+
+	price := fees.Price
+
+	if fees.Dynamic() {
+		txData = &ethtypes.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: new(big.Int).SetUint64(fees.MaxTip),
+			GasFeeCap: new(big.Int).SetUint64(fees.MaxFee), Gas: gas, To: to, Value: value, Data: data}
+		price = fees.MaxFee
+	} else {
+		txData = &ethtypes.LegacyTx{Nonce: nonce, GasPrice: new(big.Int).SetUint64(price), Gas: gas, To: to,
+			Value: value, Data: data}
+	}
+
+	tx, err := ethtypes.SignNewTx(prv, ethtypes.LatestSignerForChainID(chainID), txData)
+	if err != nil {
+		return fee, nil, fmt.Errorf("cannot sign transaction: %w", err)
+	}
+
+	fee.SetUint64(price).Mul(fee, new(big.Int).SetUint64(gas))
+
+	if !dryRun {
+		if err = e.sendRaw(tx); err != nil {
+			return fee, nil, err
+		}
+	}
+
+	return fee, tx.Hash().Bytes(), nil
+}
+
+// feeBlocks is the number of blocks whose fee history is used to estimate the fees of a transaction.
+const feeBlocks = 10
+
+// errLegacy is returned by feeHistory when the network does not support EIP-1559 dynamic fees.
+var errLegacy = errors.New("ethereum: the network does not support dynamic fees")
+
+// fees loads the fees of a transaction that are not set. If a gas price is set, a legacy transaction is sent.
+// Otherwise, the priority fee is the median of the priority fees paid in the last blocks and the maximum fee is twice
+// the base fee of the next block plus the priority fee, so the transaction is still valid if the base fee raises in
+// the next blocks. If the network does not support dynamic fees, the gas price is the one informed by the node.
+func (e *Ethereum) fees(f *types.Fees) error {
+	if f.Price != 0 {
+		if f.Dynamic() {
+			return fmt.Errorf("%w: a gas price cannot be set with dynamic fees", types.ErrFees)
+		}
+
+		return nil
+	}
+
+	if f.MaxFee == 0 || f.MaxTip == 0 {
+		base, tip, err := e.feeHistory()
+		if err != nil {
+			if !f.Dynamic() && errors.Is(err, errLegacy) {
+				f.Price, err = e.quantity("eth_gasPrice")
+			}
+
+			return err
+		}
+
+		if f.MaxTip == 0 {
+			f.MaxTip = tip
+			if f.MaxFee != 0 && f.MaxTip > f.MaxFee {
+				f.MaxTip = f.MaxFee
+			}
+		}
+
+		if f.MaxFee == 0 {
+			f.MaxFee = 2*base + f.MaxTip //nolint:gomnd // room for the base fee to double
+		}
+	}
+
+	if f.MaxTip > f.MaxFee {
+		return fmt.Errorf("%w: the priority fee %d exceeds the maximum fee %d", types.ErrFees, f.MaxTip, f.MaxFee)
+	}
+
+	return nil
+}
+
+// feeHistory returns the base fee of the next block and the median of the priority fees paid (50th percentile of
+// each block) in the last blocks, or errLegacy if the network does not support dynamic fees: the node does not have
+// eth_feeHistory or does not inform the base fee.
+func (e *Ethereum) feeHistory() (base, tip uint64, err error) {
+	var res struct {
+		BaseFee []string   `json:"baseFeePerGas"`
+		Reward  [][]string `json:"reward"`
+	}
+
+	err = e.rpc.Call("eth_feeHistory", []interface{}{hexutil.EncodeUint64(feeBlocks), "latest", []int{50}}, &res)
+	if err != nil {
+		if notFound(err) {
+			return 0, 0, fmt.Errorf("%w: %v", errLegacy, err)
+		}
+
+		return 0, 0, fmt.Errorf("cannot get fee history: %w", err)
+	}
+	// the base fee of the next block is the last one
+	if len(res.BaseFee) > 0 {
+		base, _ = strconv.ParseUint(res.BaseFee[len(res.BaseFee)-1], 0, 64)
+	}
+
+	if base == 0 {
+		return 0, 0, errLegacy
+	}
+
+	tips := make([]uint64, 0, len(res.Reward))
+
+	for _, r := range res.Reward {
+		if len(r) == 0 {
+			continue
+		}
+
+		t, err := strconv.ParseUint(r[0], 0, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot decode priority fee %s: %w", r[0], err)
+		}
+
+		tips = append(tips, t)
+	}
+
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i] < tips[j] })
+		tip = tips[len(tips)/2]
+	}
+
+	return base, tip, nil
+}
+
+// notFound checks if an error replied by the node is because the method called does not exist (JSON-RPC error -32601),
+// which nodes inform with different messages.
+func notFound(err error) bool {
+	msg := strings.ToLower(err.Error())
+
+	return strings.Contains(msg, "-32601") || strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "does not exist") || strings.Contains(msg, "is not available")
+}
+
+// estimateGas returns the gas needed by a transaction, a contract creation if 'to' is nil.
+func (e *Ethereum) estimateGas(from string, to *common.Address, value *big.Int, data []byte) (uint64, error) {
+	call := map[string]interface{}{"from": from, "value": hexutil.EncodeBig(value), "data": hexutil.Encode(data)}
+	if to != nil {
+		call["to"] = to.Hex()
+	}
+
+	return e.quantity("eth_estimateGas", call)
+}
+
+// quantity returns the hexadecimal quantity (nonce, gas, price...) returned by a call to the node.
+func (e *Ethereum) quantity(method string, params ...interface{}) (uint64, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	var res string
+	if err := e.rpc.Call(method, params, &res); err != nil {
+		return 0, fmt.Errorf("cannot call %s: %w", method, err)
+	}
+
+	n, err := strconv.ParseUint(res, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode result %s of %s: %w", res, method, err)
+	}
+
+	return n, nil
 }
 
-// Get returns the details of the transaction for the given hash.
+// sendRaw sends a signed transaction to the node, returning types.ErrNonce if its nonce has already been used.
+func (e *Ethereum) sendRaw(tx *ethtypes.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("cannot encode transaction: %w", err)
+	}
+
+	var hash string
+	if err = e.rpc.Call("eth_sendRawTransaction", []interface{}{hexutil.Encode(raw)}, &hash); err != nil {
+		// the nonce has been used by a transaction mined or pending
+		if msg := strings.ToLower(err.Error()); strings.Contains(msg, "nonce too low") ||
+			strings.Contains(msg, "replacement transaction underpriced") {
+			return fmt.Errorf("%w: %d sending transaction %s: %v", types.ErrNonce, tx.Nonce(), tx.Hash().Hex(), err)
+		}
+
+		return fmt.Errorf("cannot send transaction %s: %w", tx.Hash().Hex(), err)
+	}
+
+	return nil
+}
+
+// chainID returns the chain id configured for the network or, if not configured, the one informed by the node.
+func (e *Ethereum) chainID() (*big.Int, error) {
+	if e.conf.ChainID != 0 {
+		return new(big.Int).SetUint64(e.conf.ChainID), nil
+	}
+
+	id, err := e.quantity("eth_chainId")
+	if err != nil {
+		return nil, err
+	}
+
+	if id == 0 {
+		return nil, ErrChainID
+	}
+
+	return new(big.Int).SetUint64(id), nil
+}
+
+// Receipt loads the status, gas used, effective gas price and fee of a mined transaction from its receipt, and the
+// address of the contract created by a contract creation transaction. If the node does not have the receipt yet,
+// types.ErrNoReceipt is returned.
+func (e *Ethereum) Receipt(tx *types.Trans) error {
+	var r map[string]interface{}
+
+	if err := e.c.GetTransactionReceipt(tx.Hash, &r); err != nil {
+		if errors.Is(err, ethcli.ErrNoTrx) {
+			return fmt.Errorf("%w: %s", types.ErrNoReceipt, tx.Hash)
+		}
+
+		return fmt.Errorf("cannot get receipt for hash %s: %w", tx.Hash, err)
+	}
+
+	return decodeReceipt(tx, r)
+}
+
+// decodeReceipt loads the status, gas used, effective gas price, fee and created contract of a transaction from the
+// receipt data. It is used after a call to GetTransactionReceipt.
+func decodeReceipt(tx *types.Trans, r map[string]interface{}) error {
+	if hash, ok := r["transactionHash"].(string); !ok || hash != tx.Hash {
+		return fmt.Errorf("%w: %s", types.ErrWrongReceipt, tx.Hash)
+	}
+	// status: for ethereum 0=failed 1=success
+	tmp, ok := r["status"].(string)
+	if !ok {
+		return types.ErrNoTrxStatus
+	}
+
+	status, err := strconv.ParseUint(tmp, 0, 8)
+	if err != nil {
+		return fmt.Errorf("cannot decode status %s: %w", tmp, err)
+	}
+
+	gasUsed, ok := r["gasUsed"].(string)
+	if !ok {
+		return types.ErrNoTrxGasUsed
+	}
+
+	gas, err := strconv.ParseUint(gasUsed, 0, 64)
+	if err != nil {
+		return fmt.Errorf("cannot decode gas used %s: %w", gasUsed, err)
+	}
+	// effective gas price is not informed by nodes previous to EIP-1559, then the gas price of the transaction is kept
+	if tmp, ok = r["effectiveGasPrice"].(string); ok {
+		if tx.Price, err = strconv.ParseUint(tmp, 0, 64); err != nil {
+			return fmt.Errorf("cannot decode effective gas price %s: %w", tmp, err)
+		}
+	}
+
+	tx.Status = types.TxFailed
+	if status == 1 {
+		tx.Status = types.TxSuccess
+	}
+
+	tx.Gas = gasUsed
+	tx.Fee = txFee(gas, tx.Price)
+	tx.Contract, _ = r["contractAddress"].(string) // null unless the transaction is a contract creation
+
+	return nil
+}
+
+// Get returns the details of the transaction for the given hash. Once mined, the price and fee are the effective ones
+// informed in its receipt.
 func (e *Ethereum) Get(hash string) (*types.Trans, error) {
 	trx, err := e.c.GetTrx(hash)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get transaction for hash %s: %w", hash, err)
 	}
+	// the gas price of a pending dynamic fee transaction is its maximum fee
+	if trx.Blk != 0 {
+		if price, ok := e.effectivePrice(hash); ok {
+			trx.Price, trx.Fee = price, txFee(trx.Gas, price)
+		}
+	}
 
 	return &types.Trans{
 		Block:  strconv.FormatUint(trx.Blk, 10),
@@ -307,3 +930,79 @@ func (e *Ethereum) Get(hash string) (*types.Trans, error) {
 		TS:     uint32(trx.TS),
 	}, nil
 }
+
+// txFee returns the fee of a transaction using 'gas' at 'price' per gas, saturated to the maximum uint64 (about 18.4
+// ether) if it does not fit.
+func txFee(gas, price uint64) uint64 {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gas), new(big.Int).SetUint64(price))
+	if !fee.IsUint64() {
+		return math.MaxUint64
+	}
+
+	return fee.Uint64()
+}
+
+// effectivePrice returns the effective gas price of a mined transaction informed in its receipt, if any.
+func (e *Ethereum) effectivePrice(hash string) (uint64, bool) {
+	var r map[string]interface{}
+	if err := e.c.GetTransactionReceipt(hash, &r); err != nil {
+		return 0, false
+	}
+
+	tmp, ok := r["effectiveGasPrice"].(string)
+	if !ok {
+		return 0, false
+	}
+
+	price, err := strconv.ParseUint(tmp, 0, 64)
+
+	return price, err == nil
+}
+
+// Address validates an address returning its canonical form: 0x followed by 40 hexadecimal digits in lowercase. Mixed
+// case addresses have to have a valid EIP-55 checksum, otherwise types.ErrChecksum is returned.
+func (e *Ethereum) Address(addr string) (string, error) {
+	if len(addr) != 42 || (addr[:2] != "0x" && addr[:2] != "0X") { // 42 = 0x + 20 bytes
+		return "", fmt.Errorf("%w: %s has to be 0x followed by 40 hexadecimal digits", types.ErrAddress, addr)
+	}
+
+	if _, err := hex.DecodeString(addr[2:]); err != nil {
+		return "", fmt.Errorf("%w: %s has to be 0x followed by 40 hexadecimal digits", types.ErrAddress, addr)
+	}
+
+	lower := "0x" + strings.ToLower(addr[2:])
+	if addr[2:] != lower[2:] && addr[2:] != strings.ToUpper(addr[2:]) && Checksum(lower) != "0x"+addr[2:] {
+		return "", fmt.Errorf("%w: %s", types.ErrChecksum, addr)
+	}
+
+	return lower, nil
+}
+
+// Checksum returns the EIP-55 mixed case checksum form of an address.
+func (e *Ethereum) Checksum(addr string) string {
+	return Checksum(addr)
+}
+
+// Checksum returns the EIP-55 mixed case checksum form of an address: the hex digits of the address are uppercased
+// when the corresponding hex digit of the keccak-256 hash of the lowercase address is 8 or higher. Addresses that are
+// not 0x followed by 40 hexadecimal digits are returned as they are.
+func Checksum(addr string) string {
+	if len(addr) != 42 || addr[:2] != "0x" {
+		return addr
+	}
+
+	lower := strings.ToLower(addr[2:])
+
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	c := []byte(lower)
+	for i := range c {
+		if c[i] >= 'a' && hash[i] >= '8' {
+			c[i] -= 'a' - 'A'
+		}
+	}
+
+	return "0x" + string(c)
+}
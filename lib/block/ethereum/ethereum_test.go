@@ -1,11 +1,25 @@
 package ethereum
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
 )
 
 // block contains the sample data to decode.
-var block = map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9b", "parentHash": "0x25e2e6cfc2f49ef320c652d91a7bea99a2d115d29ea832631e5f11911a463158", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952da9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []interface{}{map[string]interface{}{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "gas": "0x47b760", "gasPrice": "0x174876e800", "hash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "input": "0x4bdb8ab50804004410241002040000c60890801000000000000000000000000000000000", "nonce": "0x46", "r": "0xdd38a14e41b886d156a1073cc7ae914f4ee70d282925652b366bf953311d5862", "s": "0x4ecacbcef27ca7ebb7f8f628036a555f934a124063869fa8ba256ef7731218cf", "to": "0x7762440182222620a7435195208038708d27ee41", "transactionIndex": "0x0", "v": "0x1c", "value": "0x0"}, map[string]interface{}{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}} //nolint:gochecknoglobals, lll // testdata
+var block = map[string]interface{}{"difficulty": "0x7ee56684", "extraData": "0x414952412f7630", "gasLimit": "0x47b784", "gasUsed": "0x47addd", "hash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "logsBloom": "0x0000000001400004002008000002000080000000000120200120002400208220000040000001000000000004804800000104000000000c0000000008201000005000200000010000140000084000000000000000100010400000080000040080100082000000000000000000004000021000800400802000000000501000000200000400000200020040010040000010105000000000040120000008000800200801000008004000000400004040000100000000000400000d005000020000008000004280010000000000000000000020010180100000140000000000020000000000000000008008000000000040000040100004001002c040000000000000", "miner": "0x00d8ae40d9a06d0e7a2877b62e32eb959afbe16d", "mixHash": "0xd93c06ec00e2c653b7958114ba8224aad8749caf8de6aee2c2f465c5f09cc0cc", "nonce": "0x34b98c94071402d8", "number": "0x29bf9b", "parentHash": "0x25e2e6cfc2f49ef320c652d91a7bea99a2d115d29ea832631e5f11911a463158", "receiptsRoot": "0x0506189cdc814f4440690b43aaf7cf278a9b346b8ef3174c03dde2d23aa820ea", "sha3Uncles": "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347", "size": "0x299a", "stateRoot": "0xf8be81979f9a92cd123f8e6295dca2660184df4f58e275c6c9fe7adee0016e7c", "timestamp": "0x5a952da9", "totalDifficulty": "0x1bd6b7e3c7b473", "transactions": []interface{}{map[string]interface{}{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0xc4581843a8dacd100c7d435bb00b2a20d038e31d", "gas": "0x47b760", "gasPrice": "0x174876e800", "hash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "input": "0x4bdb8ab50804004410241002040000c60890801000000000000000000000000000000000", "nonce": "0x46", "r": "0xdd38a14e41b886d156a1073cc7ae914f4ee70d282925652b366bf953311d5862", "s": "0x4ecacbcef27ca7ebb7f8f628036a555f934a124063869fa8ba256ef7731218cf", "to": "0x7762440182222620a7435195208038708d27ee41", "transactionIndex": "0x0", "v": "0x1c", "value": "0x0"}, map[string]interface{}{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0x1cd434711fbae1f2d9c70001409fd82d71fdccaa", "gas": "0xff59", "gasPrice": "0x98bca5a00", "hash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "input": "0x", "nonce": "0x0", "r": "0xb506e6cf81364d01c126028ec0acb771ca372269c8b157e551238a1e2d1b7ecb", "s": "0x2d7ea699220630938f57fe05fa581abd5a21f3aa105668a7128fba49598bbd70", "to": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "transactionIndex": "0x1", "v": "0x29", "value": "0x16345785d8a0000"}, map[string]interface{}{"blockHash": "0xd44a255e40eee23bd90a54a792f7a35c175400958de22a9bbfe08a7b2c244ed6", "blockNumber": "0x29bf9b", "from": "0x357dd3856d856197c1a000bbab4abcb97dfc92c4", "gas": "0x2dc6c0", "gasPrice": "0x3b9aca00", "hash": "0x6e2b8a1c4f4e0b5d5a1a8f0c3b0c6f2a9d3e7b1c5a9f2e4d6c8b0a1f3e5d7c9b", "input": "0x6080604052348015600f57600080fd5b50603f80601d6000396000f3fe6080604052600080fdfea164736f6c6343000806000a", "nonce": "0x3", "r": "0x1", "s": "0x1", "to": nil, "transactionIndex": "0x2", "v": "0x29", "value": "0x0"}}, "transactionsRoot": "0x08e95959ada5ebbe3aae1a4b9179f811c326c0969b7a5fea75b4e427c2870f96", "uncles": []string{}} //nolint:gochecknoglobals, lll // testdata
 
 // TestEthereum tests the DecodeBlock and DecodeTxs functions only as the other are direct calls to the ethcli package.
 func TestEthereum(t *testing.T) {
@@ -20,9 +34,333 @@ func TestEthereum(t *testing.T) {
 	}
 
 	txs, err := e.DecodeTxs(block) // (txs []types.Trans, err error)
-	if err != nil || (len(txs) != 2 ||
+	if err != nil || (len(txs) != 3 ||
 		txs[0].Hash != "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65" ||
-		txs[1].Hash != "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60") {
+		txs[1].Hash != "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60" ||
+		txs[2].Kind != types.KindCreate || txs[2].To != "" ||
+		txs[2].From != "0x357dd3856d856197c1a000bbab4abcb97dfc92c4" || txs[2].Data[:10] != "0x60806040") {
 		t.Errorf("DecodeTxs error:%e txs:%+v", err, txs)
 	}
 }
+
+// TestDecodeReceipt tests decodeReceipt for a successful and a reverted transaction, and a fee above the maximum.
+func TestDecodeReceipt(t *testing.T) {
+	tx := types.Trans{Hash: "0x01", Gas: "0xff59", Price: 2000000000}
+
+	err := decodeReceipt(&tx, map[string]interface{}{"transactionHash": "0x01", "status": "0x1", "gasUsed": "0x5208",
+		"effectiveGasPrice": "0x3b9aca00"})
+	if err != nil || tx.Status != types.TxSuccess || tx.Gas != "0x5208" || tx.Price != 1000000000 ||
+		tx.Fee != 21000000000000 {
+		t.Errorf("decodeReceipt error:%e tx:%+v", err, tx)
+	}
+
+	// nodes previous to EIP-1559 do not inform the effective gas price
+	tx = types.Trans{Hash: "0x02", Gas: "0xff59", Price: 2000000000}
+
+	err = decodeReceipt(&tx, map[string]interface{}{"transactionHash": "0x02", "status": "0x0", "gasUsed": "0x7530"})
+	if err != nil || tx.Status != types.TxFailed || tx.Gas != "0x7530" || tx.Price != 2000000000 ||
+		tx.Fee != 60000000000000 {
+		t.Errorf("decodeReceipt error:%e tx:%+v", err, tx)
+	}
+
+	// contract creation
+	tx = types.Trans{Hash: "0x03", Price: 1000000000, Kind: types.KindCreate}
+
+	err = decodeReceipt(&tx, map[string]interface{}{"transactionHash": "0x03", "status": "0x1", "gasUsed": "0x1a2b3",
+		"contractAddress": "0x7762440182222620a7435195208038708d27ee41"})
+	if err != nil || tx.Status != types.TxSuccess || tx.Contract != "0x7762440182222620a7435195208038708d27ee41" {
+		t.Errorf("decodeReceipt error:%e tx:%+v", err, tx)
+	}
+
+	// the fee does not overflow
+	tx = types.Trans{Hash: "0x04"}
+
+	err = decodeReceipt(&tx, map[string]interface{}{"transactionHash": "0x04", "status": "0x1", "gasUsed": "0x1c9c380",
+		"effectiveGasPrice": "0x1d1a94a2000"})
+	if err != nil || tx.Fee != math.MaxUint64 {
+		t.Errorf("decodeReceipt error:%e tx:%+v", err, tx)
+	}
+
+	err = decodeReceipt(&tx, map[string]interface{}{"transactionHash": "0x01", "status": "0x1", "gasUsed": "0x5208"})
+	if !errors.Is(err, types.ErrWrongReceipt) {
+		t.Errorf("decodeReceipt should have failed, err:%e", err)
+	}
+}
+
+// TestDecodeTransfers tests decodeTransfers only decodes ERC20 Transfer logs.
+func TestDecodeTransfers(t *testing.T) {
+	//nolint:lll // test data
+	logs := []interface{}{
+		// ERC20 transfer
+		map[string]interface{}{"address": "0x7762440182222620a7435195208038708d27ee41", "blockNumber": "0x29bf9c", "transactionHash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "logIndex": "0x3", "removed": false,
+			"topics": []interface{}{TransferTopic, "0x000000000000000000000000357dd3856d856197c1a000bbab4abcb97dfc92c4", "0x0000000000000000000000001ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83"},
+			"data":   "0x000000000000000000000000000000000000000000000000000012309ce54000"},
+		// removed log
+		map[string]interface{}{"address": "0x7762440182222620a7435195208038708d27ee41", "blockNumber": "0x29bf9c", "transactionHash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "logIndex": "0x4", "removed": true,
+			"topics": []interface{}{TransferTopic, "0x000000000000000000000000357dd3856d856197c1a000bbab4abcb97dfc92c4", "0x0000000000000000000000001ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83"},
+			"data":   "0x000000000000000000000000000000000000000000000000000012309ce54000"},
+		// ERC721 transfer
+		map[string]interface{}{"address": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "blockNumber": "0x29bf9c", "transactionHash": "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65", "logIndex": "0x5", "removed": false,
+			"topics": []interface{}{TransferTopic, "0x000000000000000000000000357dd3856d856197c1a000bbab4abcb97dfc92c4", "0x0000000000000000000000001ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83", "0x0000000000000000000000000000000000000000000000000000000000000001"},
+			"data":   "0x"},
+		// zero value transfer
+		map[string]interface{}{"address": "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", "blockNumber": "0x29bf9c", "transactionHash": "0xdbd3184b2f947dab243071000df22cf5acc6efdce90a04aaf057521b1ee5bf60", "logIndex": "0x6", "removed": false,
+			"topics": []interface{}{TransferTopic, "0x0000000000000000000000001ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83", "0x000000000000000000000000357dd3856d856197c1a000bbab4abcb97dfc92c4"},
+			"data":   "0x0000000000000000000000000000000000000000000000000000000000000000"},
+	}
+
+	txs, err := decodeTransfers(logs)
+	if err != nil || len(txs) != 2 ||
+		txs[0].Kind != types.KindToken || txs[0].Token != "0x7762440182222620a7435195208038708d27ee41" ||
+		txs[0].From != "0x357dd3856d856197c1a000bbab4abcb97dfc92c4" ||
+		txs[0].To != "0x1ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83" || txs[0].Value != "0x12309ce54000" ||
+		txs[0].LogIndex != "0x3" || txs[0].Block != "0x29bf9c" ||
+		txs[1].Value != "0x0" || txs[1].LogIndex != "0x6" {
+		t.Errorf("decodeTransfers error:%e txs:%+v", err, txs)
+	}
+
+	if _, err = decodeTransfers([]interface{}{"0x"}); !errors.Is(err, types.ErrLogDecode) {
+		t.Errorf("decodeTransfers should have failed, err:%e", err)
+	}
+}
+
+// TestDecodeTraces tests the internal transfers decoded from debug and parity traces.
+func TestDecodeTraces(t *testing.T) {
+	const (
+		a = "0x357dd3856d856197c1a000bbab4abcb97dfc92c4"
+		b = "0x1ee49d37ab544a0068d0bb8dc7b76ee8e7e4ec83"
+		c = "0x7762440182222620a7435195208038708d27ee41"
+		h = "0xc39f3c2c2b5c0a772e8605bbeef7d341937b85e739a3c55d1e7384ac88f31c65"
+	)
+
+	blk := types.Block{Number: "0x29bf9c", Tx: []types.Trans{{Hash: h}}}
+	// a calls c which pays b, delegates to b, and calls a reverted b that pays a
+	debug := []interface{}{
+		map[string]interface{}{"result": map[string]interface{}{"type": "CALL", "from": a, "to": c, "value": "0x1",
+			"calls": []interface{}{
+				map[string]interface{}{"type": "CALL", "from": c, "to": b, "value": "0x5"},
+				map[string]interface{}{"type": "DELEGATECALL", "from": c, "to": b, "value": "0x5"},
+				map[string]interface{}{"type": "CALL", "from": c, "to": b, "value": "0x0", "error": "execution reverted",
+					"calls": []interface{}{map[string]interface{}{"type": "CALL", "from": b, "to": a, "value": "0x2"}}},
+				map[string]interface{}{"type": "CALL", "from": c, "to": b, "value": "0x0",
+					"calls": []interface{}{map[string]interface{}{"type": "CALL", "from": b, "to": a, "value": "0x3"}}},
+			}}},
+	}
+
+	txs, err := decodeCallTraces(nil, blk, debug)
+	if err != nil || len(txs) != 2 ||
+		txs[0].Kind != types.KindInternal || txs[0].Hash != h || txs[0].Block != blk.Number ||
+		txs[0].From != c || txs[0].To != b || txs[0].Value != "0x5" || txs[0].Trace != "0" ||
+		txs[1].From != b || txs[1].To != a || txs[1].Value != "0x3" || txs[1].Trace != "3-0" {
+		t.Errorf("decodeCallTraces error:%e txs:%+v", err, txs)
+	}
+
+	parity := []interface{}{
+		map[string]interface{}{"type": "call", "transactionHash": h, "traceAddress": []interface{}{},
+			"action": map[string]interface{}{"callType": "call", "from": a, "to": c, "value": "0x1"}},
+		map[string]interface{}{"type": "call", "transactionHash": h, "traceAddress": []interface{}{0.0},
+			"action": map[string]interface{}{"callType": "call", "from": c, "to": b, "value": "0x5"}},
+		map[string]interface{}{"type": "call", "transactionHash": h, "traceAddress": []interface{}{1.0},
+			"action": map[string]interface{}{"callType": "delegatecall", "from": c, "to": b, "value": "0x5"}},
+		map[string]interface{}{"type": "call", "transactionHash": h, "traceAddress": []interface{}{2.0}, "error": "Reverted",
+			"action": map[string]interface{}{"callType": "call", "from": c, "to": b, "value": "0x0"}},
+		map[string]interface{}{"type": "call", "transactionHash": h, "traceAddress": []interface{}{2.0, 0.0},
+			"action": map[string]interface{}{"callType": "call", "from": b, "to": a, "value": "0x2"}},
+		map[string]interface{}{"type": "suicide", "transactionHash": h, "traceAddress": []interface{}{3.0},
+			"action": map[string]interface{}{"address": b, "refundAddress": a, "balance": "0x3"}},
+	}
+
+	txs, err = decodeParityTraces(nil, blk, parity)
+	if err != nil || len(txs) != 2 ||
+		txs[0].Kind != types.KindInternal || txs[0].Hash != h || txs[0].Block != blk.Number ||
+		txs[0].From != c || txs[0].To != b || txs[0].Value != "0x5" || txs[0].Trace != "0" ||
+		txs[1].From != b || txs[1].To != a || txs[1].Value != "0x3" || txs[1].Trace != "3" {
+		t.Errorf("decodeParityTraces error:%e txs:%+v", err, txs)
+	}
+
+	if _, err = decodeCallTraces(nil, blk, []interface{}{"0x"}); !errors.Is(err, types.ErrTraceDecode) {
+		t.Errorf("decodeCallTraces should have failed, err:%e", err)
+	}
+}
+
+// TestAddress tests the validation of addresses and their EIP-55 checksum.
+func TestAddress(t *testing.T) {
+	e := &Ethereum{}
+
+	for _, a := range []string{ // EIP-55 test vectors
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if c := e.Checksum(strings.ToLower(a)); c != a {
+			t.Errorf("Checksum error for %s:%s", a, c)
+		}
+
+		if c, err := e.Address(a); err != nil || c != strings.ToLower(a) {
+			t.Errorf("Address error for %s:%s %e", a, c, err)
+		}
+	}
+
+	cases := []struct {
+		addr, canonical string
+		err             error
+	}{
+		{"0xcba75f167b03e34b8a572c50273c082401b073ed", "0xcba75f167b03e34b8a572c50273c082401b073ed", nil},
+		{"0xCBA75F167B03E34B8A572C50273C082401B073ED", "0xcba75f167b03e34b8a572c50273c082401b073ed", nil},
+		{"0xcba75F167B03e34B8a572c50273C082401b073Ed", "0xcba75f167b03e34b8a572c50273c082401b073ed", nil},
+		{"0xCba75F167B03e34B8a572c50273C082401b073Ed", "", types.ErrChecksum},
+		{"0x", "", types.ErrAddress},
+		{"0xcba75f167b03e34b8a572c50273c082401b073eg", "", types.ErrAddress},
+		{"cba75f167b03e34b8a572c50273c082401b073ed00", "", types.ErrAddress},
+	}
+
+	for _, c := range cases {
+		if a, err := e.Address(c.addr); a != c.canonical || !errors.Is(err, c.err) {
+			t.Errorf("Address error for %s:%s %e", c.addr, a, err)
+		}
+	}
+}
+
+// node is a mock ethereum node replying the results (JSON) of the methods called, or an error if the result is an
+// error, and keeping the raw transaction sent.
+type node struct {
+	results map[string]interface{}
+	sent    string
+}
+
+func (n *node) Call(method string, params, result interface{}) error {
+	if method == "eth_sendRawTransaction" {
+		n.sent, _ = params.([]interface{})[0].(string)
+	}
+
+	if err, ok := n.results[method].(error); ok {
+		return err
+	}
+
+	return json.Unmarshal([]byte(n.results[method].(string)), result)
+}
+
+// TestSend tests transactions are signed for the chain id configured, or the one informed by the node otherwise, with
+// dynamic fees unless a gas price is given or the network does not support them.
+func TestSend(t *testing.T) {
+	const (
+		key   = "0101010101010101010101010101010101010101010101010101010101010101"
+		to    = "0x7762440182222620a7435195208038708d27ee41"
+		token = "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f"
+	)
+
+	prv, _ := crypto.HexToECDSA(key)
+	from := strings.ToLower(crypto.PubkeyToAddress(prv.PublicKey).Hex())
+
+	n := &node{results: map[string]interface{}{"eth_chainId": `"0xaa36a7"`, "eth_getTransactionCount": `"0x7"`,
+		"eth_gasPrice": `"0x3b9aca00"`, "eth_estimateGas": `"0x5208"`, "eth_sendRawTransaction": `"0x01"`,
+		"eth_feeHistory": `{"oldestBlock":"0x10","baseFeePerGas":["0x3b9aca00","0x3b9aca00","0x3b9aca00","0x3b9aca00"],
+			"reward":[["0x1"],["0x5f5e100"],["0x2faf080"]]}`}}
+
+	for _, c := range []struct {
+		name    string
+		chainID uint64
+		token   string
+		fees    types.Fees
+		legacy  bool // the network does not support dynamic fees
+		expID   int64
+		expType uint8
+		expFees types.Fees
+		expTo   string
+		expData string
+	}{
+		{"dynamic", 0, "", types.Fees{}, false, 11155111, ethtypes.DynamicFeeTxType,
+			types.Fees{MaxFee: 2050000000, MaxTip: 50000000}, to, ""},
+		{"price", 31337, "", types.Fees{Price: 2000000000}, false, 31337, ethtypes.LegacyTxType,
+			types.Fees{Price: 2000000000}, to, ""},
+		{"legacy", 0, "", types.Fees{}, true, 11155111, ethtypes.LegacyTxType, types.Fees{Price: 1000000000}, to, ""},
+		{"tip", 137, token, types.Fees{MaxTip: 1000000000}, false, 137, ethtypes.DynamicFeeTxType,
+			types.Fees{MaxFee: 3000000000, MaxTip: 1000000000}, token, "a9059cbb000000000000000000000000" + to[2:] +
+				"00000000000000000000000000000000000000000000000000000000000f4240"},
+		{"maxFee", 137, "", types.Fees{MaxFee: 10000000}, false, 137, ethtypes.DynamicFeeTxType,
+			types.Fees{MaxFee: 10000000, MaxTip: 10000000}, to, ""},
+	} {
+		e := &Ethereum{rpc: n, conf: config.BlockConfig{Name: c.name, ChainID: c.chainID}}
+
+		history := n.results["eth_feeHistory"]
+		if c.legacy {
+			n.results["eth_feeHistory"] = errors.New("the method eth_feeHistory does not exist")
+		}
+
+		fees := c.fees
+		fee, hash, err := e.Send(from, to, c.token, "0xf4240", nil, key, &fees, false)
+		n.results["eth_feeHistory"] = history
+
+		if err != nil || fees != c.expFees {
+			t.Fatalf("%s: Send fees:%+v err:%e", c.name, fees, err)
+		}
+
+		raw, _ := hexutil.Decode(n.sent)
+		tx := new(ethtypes.Transaction)
+
+		if err = tx.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("%s: sent transaction cannot be decoded, err:%e", c.name, err)
+		}
+
+		sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil || !strings.EqualFold(sender.Hex(), from) || tx.ChainId().Int64() != c.expID ||
+			tx.Type() != c.expType || tx.Nonce() != 7 || !strings.EqualFold(tx.To().Hex(), c.expTo) ||
+			hex.EncodeToString(tx.Data()) != c.expData || !bytes.Equal(hash, tx.Hash().Bytes()) {
+			t.Errorf("%s: sent transaction from %s err:%v tx:%+v", c.name, sender.Hex(), err, tx)
+		}
+		// the fee is the maximum fee of the transaction
+		if fee.Cmp(new(big.Int).Mul(tx.GasFeeCap(), big.NewInt(21000))) != 0 {
+			t.Errorf("%s: fee %s expected %s * 21000", c.name, fee, tx.GasFeeCap())
+		}
+	}
+
+	e := &Ethereum{rpc: n, conf: config.BlockConfig{Name: "anvil", ChainID: 31337}}
+
+	for _, c := range []struct {
+		name, from, amount, token string
+		data                      []byte
+		fees                      types.Fees
+		err                       error
+	}{
+		{"key", to, "0x1", "", nil, types.Fees{}, ErrKey},
+		{"amount", from, "one", "", nil, types.Fees{}, ErrAmount},
+		{"tokenData", from, "0x1", token, []byte{1}, types.Fees{}, types.ErrSendTokenData},
+		{"priceAndTip", from, "0x1", "", nil, types.Fees{Price: 1, MaxTip: 1}, types.ErrFees},
+		{"tipAboveMax", from, "0x1", "", nil, types.Fees{MaxFee: 1, MaxTip: 2}, types.ErrFees},
+	} {
+		if _, _, err := e.Send(c.from, to, c.token, c.amount, c.data, key, &c.fees, true); !errors.Is(err, c.err) {
+			t.Errorf("%s: Send err:%e", c.name, err)
+		}
+	}
+	// only a node without fee history sends legacy transactions, other errors are returned
+	history := n.results["eth_feeHistory"]
+	n.results["eth_feeHistory"] = errors.New("503 Service Unavailable")
+
+	if _, _, err := e.Send(from, to, "", "0x1", nil, key, &types.Fees{}, true); err == nil ||
+		errors.Is(err, errLegacy) {
+		t.Errorf("Send without fee history err:%e", err)
+	}
+
+	n.results["eth_feeHistory"] = history
+	// the nonce has been used
+	n.results["eth_sendRawTransaction"] = errors.New("nonce too low: next nonce 8, tx nonce 7")
+
+	_, _, err := e.SendNonce(from, to, "", "0x1", nil, key, &types.Fees{}, 7, false)
+	if !errors.Is(err, types.ErrNonce) {
+		t.Errorf("SendNonce nonce used err:%e", err)
+	}
+}
+
+// TestNative tests the native currency of the network is ether unless configured.
+func TestNative(t *testing.T) {
+	e := &Ethereum{conf: config.BlockConfig{Name: "sepolia"}}
+	if n := e.Native(); n.Name != "sepolia" || n.Symbol != "ETH" || n.Decimals != 18 {
+		t.Errorf("Native:%+v", n)
+	}
+
+	e.conf = config.BlockConfig{Name: "polygon", Symbol: "POL", Decimals: 18}
+	if n := e.Native(); n.Symbol != "POL" || n.Decimals != 18 {
+		t.Errorf("Native:%+v", n)
+	}
+}
@@ -3,6 +3,8 @@ package types
 
 import (
 	"errors"
+	"math/big"
+	"strings"
 )
 
 // Token is a blockchain asset.
@@ -28,8 +30,134 @@ type Trans struct {
 	Fee    uint64 `json:"fee"`
 	Status uint8  `json:"status"`
 	TS     uint32 `json:"ts"`
+	// MaxFee and MaxTip are the maximum fee and priority fee per gas of an EIP-1559 dynamic fee transaction
+	MaxFee uint64 `json:"maxFeePerGas,omitempty"`
+	MaxTip uint64 `json:"maxPriorityFeePerGas,omitempty"`
+	// BlockHash is the hash of the block the transaction was mined in
+	BlockHash string `json:"blockHash,omitempty"`
+	// Kind is the kind of transaction, see Kind* constants (empty for transfers)
+	Kind string `json:"kind,omitempty"`
+	// Contract is the address of the contract created by a KindCreate transaction
+	Contract string `json:"contract,omitempty"`
+	// LogIndex is the index in the block of the log of a KindToken transfer
+	LogIndex string `json:"logIndex,omitempty"`
+	// Trace is the address of the call in the transaction's call tree of a KindInternal transfer (ie. "0-1")
+	Trace string `json:"traceAddress,omitempty"`
+	// Output is the index of the output of a UTXO transaction (ie. bitcoin) paying to To
+	Output string `json:"output,omitempty"`
+	// fields set by the explorer when sending events
+	Event    string `json:"event,omitempty"`       // event type, see Ev* constants
+	Removed  string `json:"removedFrom,omitempty"` // hash of the orphaned block the transaction was removed from
+	Replaced string `json:"replacedBy,omitempty"`  // hash of the canonical block that replaced the orphaned block
+	Subs     []Sub  `json:"subs,omitempty"`        // subscriptions that triggered the event
 }
 
+// Fees are the fees offered by a transaction to be sent. Price is the gas price of a legacy transaction (or the fee
+// rate of UTXO chains), and MaxFee and MaxTip the maximum fee and priority fee per gas of an EIP-1559 dynamic fee
+// transaction. The fees not set are estimated.
+type Fees struct {
+	Price  uint64
+	MaxFee uint64
+	MaxTip uint64
+}
+
+// Dynamic checks if the fees are the ones of an EIP-1559 dynamic fee transaction.
+func (f Fees) Dynamic() bool {
+	return f.MaxFee != 0 || f.MaxTip != 0
+}
+
+// Sub is the subscription record of a monitored object (address or transaction). It is echoed in the events the
+// object triggers, so consumers know which subscription fired.
+type Sub struct {
+	Obj     string            `json:"obj,omitempty" bson:"obj,omitempty"`         // monitored object, set in events
+	Owner   string            `json:"owner,omitempty" bson:"owner,omitempty"`     // owner of the subscription (ie. userId)
+	Label   string            `json:"label,omitempty" bson:"label,omitempty"`     // client label
+	Created int64             `json:"created,omitempty" bson:"created,omitempty"` // unix time of creation
+	Dir     string            `json:"dir,omitempty" bson:"dir,omitempty"`         // direction filter, see Dir* constants
+	Meta    map[string]string `json:"meta,omitempty" bson:"meta,omitempty"`       // arbitrary client metadata
+	// Tokens filters the transfers of the token contracts listed, TokNative for the native currency (all if empty)
+	Tokens []string `json:"tokens,omitempty" bson:"tokens,omitempty"`
+	// Min filters the transfers with a lower value (0x-hexadecimal), so dust transfers are not notified
+	Min string `json:"min,omitempty" bson:"min,omitempty"`
+	// Expires is the unix time when the subscription expires and the object stops being monitored (0 never)
+	Expires int64 `json:"expires,omitempty" bson:"expires,omitempty"`
+	// Notify requests an EvExpired event to be sent when the subscription expires
+	Notify bool `json:"notify,omitempty" bson:"notify,omitempty"`
+}
+
+// Directions of the transactions that trigger an address subscription. An empty direction is DirBoth.
+const (
+	DirIn   = "in"   // transactions to the address
+	DirOut  = "out"  // transactions from the address
+	DirBoth = "both" // transactions to or from the address
+)
+
+// TokNative is the token of a subscription filtering the transfers of the native currency (ie. ether).
+const TokNative = "native"
+
+// Matches checks if a transaction from/to the subscription's address (given by 'out') triggers the subscription, that
+// is if it passes the direction, token and minimum value filters.
+func (s Sub) Matches(tx Trans, out bool) bool {
+	if (s.Dir == DirIn && out) || (s.Dir == DirOut && !out) {
+		return false
+	}
+
+	if len(s.Tokens) > 0 {
+		tok := tx.Token
+		if tok == "" {
+			tok = TokNative
+		}
+
+		var found bool
+		for _, t := range s.Tokens {
+			if found = strings.EqualFold(t, tok); found {
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if s.Min != "" {
+		min, ok1 := new(big.Int).SetString(strings.TrimPrefix(s.Min, "0x"), 16)
+		value, ok2 := new(big.Int).SetString(strings.TrimPrefix(tx.Value, "0x"), 16)
+
+		if ok1 && (!ok2 || value.Cmp(min) < 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Kinds of transactions.
+const (
+	KindCreate   = "create"   // contract creation
+	KindToken    = "token"    // ERC20 token transfer, got from a Transfer log of the transaction
+	KindInternal = "internal" // ether transferred by a contract, got from the call traces of the transaction
+)
+
+// Transaction status values.
+const (
+	TxPending uint8 = 0
+	TxFailed  uint8 = 1
+	TxSuccess uint8 = 2
+)
+
+// Event types sent by the explorer for monitored transactions.
+const (
+	EvSeen      = "seen"      // the transaction has been included in the last block mined
+	EvConfirmed = "confirmed" // the block of the transaction has the confirmations required by the network
+	EvRemoved   = "removed"   // the block of the transaction has been orphaned by a chain reorganization
+	EvReemitted = "reemitted" // a removed transaction has been included in a block of the canonical chain
+	EvFailed    = "failed"    // the transaction has been included in the last block mined but its execution failed
+	EvDropped   = "dropped"   // the transaction has not been included in a block before its timeout
+	EvCreated   = "created"   // a contract creation transaction has been included in the last block mined
+	EvExpired   = "expired"   // the subscription of a monitored address has expired, informed in Subs
+)
+
 // Block contains a simplified list of block fields.
 type Block struct {
 	// contains other fields, but this ones are the important to us right now...
@@ -49,6 +177,12 @@ var (
 	ErrNoParentHash  = errors.New("block data does not contain a parenthash")
 	ErrNoBlock       = errors.New("block not available yet")
 	ErrNoTrx         = errors.New("transaction not found")
+	ErrNoReceipt     = errors.New("transaction receipt not available yet")
+	ErrWrongReceipt  = errors.New("transaction receipt does not match the transaction hash")
+	ErrLogDecode     = errors.New("malformed log data")
+	ErrTraceDecode   = errors.New("malformed trace data")
+	ErrAddress       = errors.New("invalid address")
+	ErrChecksum      = errors.New("invalid address checksum")
 	ErrNoTrxHash     = errors.New("malformed tx data in block, field 'hash' missing")
 	ErrNoTrxInput    = errors.New("malformed tx data in block, field 'input' missing")
 	ErrNoTrxValue    = errors.New("malformed tx data in block, field 'value' missing")
@@ -59,4 +193,6 @@ var (
 	ErrNoTrxGasPrice = errors.New("malformed tx data in block, field 'gasPrice' missing")
 	ErrWrongAmt      = errors.New("amount length exceeds maximum (32)")
 	ErrSendTokenData = errors.New("cannot send token and data at same time")
+	ErrFees          = errors.New("invalid fees")
+	ErrNonce         = errors.New("nonce already used")
 )
@@ -38,16 +38,31 @@ var (
 	SeedDefault = "642ce4e20f09c9f4d285c2b336063eaafbe4cb06dece8134f3a64bdd8f8c0c24df73e1a2e7056359b6db61e179ff45e5ada51d14f07b30becb6d92b961d35df4" //nolint:lll // seed is 64 bytes long
 )
 
-// BlockConfig defines the required fields for blockchain/network connection configuration.
-// Node contains the url (ie. https://localhost:8545) and Secret is an optional field when Basic Authentication is
-// required by the blockchain server.
+// BlockConfig defines the required fields for blockchain/network connection configuration. Only Name, Node and
+// MaxBlocks are required, the other fields have defaults.
 type BlockConfig struct {
-	Name      string `json:"name"`
-	Node      string `json:"node"`
-	Secret    string `json:"secret"`
-	MaxBlocks int    `json:"maxBlocks"`
+	Name          string  `json:"name"`          // name of the network, ie. mainNet, sepolia or bitcoin
+	Node          string  `json:"node"`          // url of the node, ie. https://localhost:8545
+	Secret        string  `json:"secret"`        // basic authentication of the node, if required
+	MaxBlocks     int     `json:"maxBlocks"`     // blocks kept by the explorer to recover from chain reorganizations
+	Confirmations int     `json:"confirmations"` // blocks mined on top of a transaction to confirm it, < MaxBlocks
+	Rate          int     `json:"rate"`          // maximum blocks requested to the node per second, 1 if not set
+	Window        int     `json:"window"`        // blocks requested concurrently while catching up, 1 if not set
+	StartBlock    string  `json:"startBlock"`    // first block explored: a number, "latest" or "latest-N", 0 if not set
+	Trace         string  `json:"trace"`         // trace API to detect internal transfers: "debug" or "parity"
+	Type          string  `json:"type"`          // chain family (see TypeEVM), inferred from known names if not set
+	ChainID       uint64  `json:"chainId"`       // EIP-155 chain id used to sign, asked to the node if not set
+	Symbol        string  `json:"symbol"`        // symbol of the native currency, ETH or BTC if not set
+	Decimals      uint8   `json:"decimals"`      // decimals of the native currency, 18 or 8 if not set
+	BlockTime     float64 `json:"blockTime"`     // seconds between blocks (ie. 12 or 0.25), estimated if not set
 }
 
+// Chain types (families) of the networks, see BlockConfig.
+const (
+	TypeEVM     = "evm"     // ethereum and EVM compatible networks (ie. sepolia, polygon, arbitrum, anvil)
+	TypeBitcoin = "bitcoin" // bitcoin networks
+)
+
 // ServiceConfig contains the required fields for the wallet and explorer microservices. Database, API endpoint, ports,
 // SSL cert and key, message broker type and url, a slice for blockchain configs and the seed for the HD wallet.
 type ServiceConfig struct {
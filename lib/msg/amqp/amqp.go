@@ -241,7 +241,23 @@ func (r *AMQP) GetReqs(net string, mut *sync.Mutex) (<-chan msg.WalletReq, <-cha
 				log.Printf("error ack'ing request: %s", err)
 			}
 		}
+
+		close(reqs)
 	}()
 
 	return reqs, errors, nil
 }
+
+// StopReqs cancels the consumer of requests for the specified network started by GetReqs, so its requests are consumed
+// by other explorers.
+func (r *AMQP) StopReqs(net string) error {
+	if r.ch == nil {
+		return nil
+	}
+
+	if err := r.ch.Cancel("explorer-"+net, false); err != nil {
+		return fmt.Errorf("cannot cancel consumer of wr%s: %w", net, err)
+	}
+
+	return nil
+}
@@ -3,30 +3,75 @@
 package msg
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
 )
 
 // Types of object for wallet requests.
 const (
-	EXIT    = -1
+	EXIT    = -1 // stops the explorer of the network
 	ADDRESS = 0
 	TX      = 1
+	CTRL    = 2 // control command for the explorer of the network, given in Obj (see Cmd* constants)
+	NET     = 3 // network added (LISTEN) or removed (UNLISTEN) at runtime, sent to NETWORKS
 )
 
+// NETWORKS is the network the requests of type NET are sent to, so they are consumed by the explorers of any network.
+const NETWORKS = "networks"
+
+// ErrNetName is returned when a network name cannot be used, see NetName.
+var ErrNetName = errors.New("invalid network name: has to contain only letters, digits, '-' or '_'")
+
+// NetName checks a network name can be used as a name of the message broker queues, and it is not NETWORKS.
+func NetName(name string) bool {
+	if name == "" || name == NETWORKS {
+		return false
+	}
+
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Actions to be applied to objects for wallet requests.
 const (
 	LISTEN   = 0
 	UNLISTEN = 1
 )
 
+// Control commands for wallet requests of type CTRL.
+const (
+	CmdPause  = "pause"  // pauses the exploration of the network
+	CmdResume = "resume" // resumes the exploration of the network
+	CmdRewind = "rewind" // moves the explorer so the next block explored is From
+	CmdRescan = "rescan" // explores again blocks From to To, and then the blocks after the last block explored
+	CmdReload = "reload" // reloads the monitored addresses and transactions from DB
+	CmdExit   = "exit"   // object of EXIT requests
+)
+
 // WalletReq defines the message that wallet service publishes to explorer to ask to explore an object.
 type WalletReq struct {
 	Net  string `json:"net"`
 	Type int    `json:"type"` // type of object
 	Obj  string `json:"obj"`
 	Act  int    `json:"act"` // action to be applied
+	// Timeout is the number of seconds a transaction (type TX) is monitored until it is mined. If 0, it is monitored
+	// until it is mined or unlistened.
+	Timeout int64 `json:"timeout,omitempty"`
+	// Sub is the subscription record of the object to listen to, echoed in the events it triggers.
+	Sub *types.Sub `json:"sub,omitempty"`
+	// From and To are the blocks of the CmdRewind and CmdRescan control commands.
+	From uint64 `json:"from,omitempty"`
+	To   uint64 `json:"to,omitempty"`
+	// Conf is the configuration of the network added by a request of type NET.
+	Conf *config.BlockConfig `json:"conf,omitempty"`
 }
 
 type MsgBroker interface {
@@ -39,5 +84,6 @@ type MsgBroker interface {
 
 	// methods for explorer service
 	GetReqs(net string, mut *sync.Mutex) (<-chan WalletReq, <-chan error, error)
+	StopReqs(net string) error // stops consuming the requests of GetReqs, closing its requests channel
 	SendTrans(net string, t []types.Trans) error
 }
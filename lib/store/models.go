@@ -1,10 +1,16 @@
 package store
 
+import (
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
+)
+
 // Address contains the fields for an address save to DB.
 type Address struct {
-	ID   []byte `json:"id"`
-	Name string `json:"name"`
-	Addr string `json:"addr"`
+	ID   []byte    `json:"id"`
+	Name string    `json:"name"`
+	Addr string    `json:"addr"`
+	Sub  types.Sub `json:"sub"`
 }
 
 // ListenedAddresses contains the fields of monitored objects saved to DB.
@@ -13,10 +19,35 @@ type ListenedAddresses struct {
 	Addr []Address `json:"addresses"`
 }
 
+// Tx contains the fields for a monitored transaction saved to DB.
+type Tx struct {
+	Hash     string    `json:"hash" bson:"hash"`
+	Deadline int64     `json:"deadline" bson:"deadline"` // unix time when the transaction is dropped if not mined (0 never)
+	Sub      types.Sub `json:"sub" bson:"sub"`
+}
+
+// ListenedTxs contains the monitored transactions saved to DB for a network.
+type ListenedTxs struct {
+	Net string `json:"net"`
+	Txs []Tx   `json:"txs"`
+}
+
 // NetExplorer contains the fields for a NetExplorer type saved to DB.
 type NetExplorer struct {
 	Block uint64                 `json:"block" bson:"block"`
 	Bh    []string               `json:"bh" bson:"bh"`
 	Bhi   int                    `json:"bhi" bson:"bhi"`
-	Map   map[string]interface{} `json:"map" bson:"map"`
+	Ev    [][]types.Trans        `json:"ev" bson:"ev"`
+	Conf  uint64                 `json:"conf" bson:"conf"`
+	Rem   map[string]types.Trans `json:"rem" bson:"rem"`
+	// Token is the fencing token of the lease of the explorer saving it (see DB.AcquireLease), 0 if leases are not used.
+	// An explorer is not saved if it was saved with a greater token, so a former leader cannot overwrite it.
+	Token int64 `json:"token" bson:"token"`
+}
+
+// Network contains the configuration of a network added at runtime, or the name of a network removed at runtime, saved
+// to DB.
+type Network struct {
+	Conf    config.BlockConfig `json:"conf" bson:"conf"`
+	Removed bool               `json:"removed" bson:"removed"`
 }
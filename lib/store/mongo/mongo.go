@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,13 +15,15 @@ import (
 	mgo "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/tarancss/adp/lib/block/types"
 	"github.com/tarancss/adp/lib/store"
 	"github.com/tarancss/adp/lib/util"
 )
 
 // Mongo implements a connection to a MongoDB database.
 type Mongo struct {
-	c *mgo.Client
+	c       *mgo.Client
+	indexed sync.Map // collections whose lookup index has been created
 }
 
 // MongoAddress implements a store address to MongoDB.
@@ -28,11 +31,12 @@ type MongoAddress struct {
 	ID   primitive.ObjectID `json:"_id" bson:"_id"`
 	Name string             `json:"name,omitempty" bson:"name,omitempty"`
 	Addr string             `json:"address" bson:"address"`
+	Sub  types.Sub          `json:"sub" bson:"sub"`
 }
 
 // Address converts a MongoAddress to store.Address type.
 func (a MongoAddress) Address() store.Address {
-	return store.Address{ID: a.ID[:], Addr: a.Addr, Name: a.Name}
+	return store.Address{ID: a.ID[:], Addr: a.Addr, Name: a.Name, Sub: a.Sub}
 }
 
 // New returns a Mongo client connection to the specified MongoDB database uri.
@@ -54,17 +58,33 @@ func New(uri string) (*Mongo, error) {
 	return &Mongo{c: c}, nil
 }
 
+// collection returns a collection of db, creating once the index on 'key' used to look up its documents, so lookups
+// do not scan collections with millions of addresses.
+func (m *Mongo) collection(db, net, key string) *mgo.Collection {
+	col := m.c.Database(db).Collection(net)
+
+	if _, ok := m.indexed.LoadOrStore(db+"."+net, true); !ok {
+		if _, err := col.Indexes().CreateOne(context.Background(),
+			mgo.IndexModel{Keys: bson.D{{Key: key, Value: 1}}}); err != nil {
+			log.Printf("[%s] Error creating index on %s.%s: %e", net, db, key, err)
+			m.indexed.Delete(db + "." + net)
+		}
+	}
+
+	return col
+}
+
 // CloseMongo will close a database connection. Must be called at termination time.
 func (m *Mongo) CloseMongo() error {
 	return m.c.Disconnect(context.Background())
 }
 
-// AddAddress saves an address if the address does not already exist.
+// AddAddress saves an address if the address does not already exist, otherwise its subscription is updated.
 func (m *Mongo) AddAddress(a store.Address, net string) ([]byte, error) {
 	var ma MongoAddress
 	ma.Addr = a.Addr
 
-	col := m.c.Database("addr").Collection(net)
+	col := m.collection("addr", net, "address")
 
 	// try and find it
 	filter := bson.M{"address": a.Addr}
@@ -72,7 +92,7 @@ func (m *Mongo) AddAddress(a store.Address, net string) ([]byte, error) {
 
 	err := sr.Decode(&ma)
 	if errors.Is(err, mgo.ErrNoDocuments) { // if not found, do insert it!!
-		res, errIns := col.InsertOne(context.Background(), bson.M{"name": ma.Name, "address": ma.Addr})
+		res, errIns := col.InsertOne(context.Background(), bson.M{"name": ma.Name, "address": ma.Addr, "sub": a.Sub})
 		if errIns != nil {
 			return nil, fmt.Errorf("could not insert address in db: %w", errIns)
 		}
@@ -86,6 +106,10 @@ func (m *Mongo) AddAddress(a store.Address, net string) ([]byte, error) {
 
 	log.Printf("[%s] Address was already listened:%+v\n", net, ma)
 
+	if _, err = col.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"sub": a.Sub}}); err != nil {
+		return nil, fmt.Errorf("could not update address subscription in db: %w", err)
+	}
+
 	return hex.DecodeString(ma.ID.Hex())
 }
 
@@ -94,7 +118,7 @@ func (m *Mongo) RemoveAddress(a store.Address, net string) error {
 	var ma MongoAddress
 	ma.Addr = a.Addr
 
-	col := m.c.Database("addr").Collection(net)
+	col := m.collection("addr", net, "address")
 
 	filter := bson.M{"address": a.Addr}
 
@@ -141,6 +165,145 @@ func (m *Mongo) GetAddresses(net []string) ([]store.ListenedAddresses, error) {
 	return addrs, nil
 }
 
+// AddTx saves a monitored transaction, updating its deadline and subscription if it already exists.
+func (m *Mongo) AddTx(tx store.Tx, net string) error {
+	_, err := m.collection("tx", net, "hash").UpdateOne(context.Background(),
+		bson.M{"hash": tx.Hash}, // filter
+		bson.D{ // update
+			{
+				Key: "$set", Value: bson.D{
+					{Key: "deadline", Value: tx.Deadline},
+					{Key: "sub", Value: tx.Sub},
+				},
+			},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("could not save transaction in db: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTx deletes a monitored transaction from the database.
+func (m *Mongo) RemoveTx(tx store.Tx, net string) error {
+	res, err := m.collection("tx", net, "hash").DeleteOne(context.Background(), bson.M{"hash": tx.Hash})
+	if err == nil && res.DeletedCount != 1 {
+		err = store.ErrTxNotFound
+	}
+
+	return err
+}
+
+// GetTxs returns the transactions monitored for the network or blockchains indicated in the net slice.
+func (m *Mongo) GetTxs(net []string) ([]store.ListenedTxs, error) {
+	cols, err := m.c.Database("tx").ListCollections(context.Background(), bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting mongo DB object: %w", err)
+	}
+
+	txs := []store.ListenedTxs{}
+
+	for cols.Next(context.Background()) {
+		col := cols.Current.Lookup("name").String()
+		col = col[1 : len(col)-1]
+
+		if len(net) == 0 || util.In(net, col) {
+			var lt store.ListenedTxs
+			// get the transactions
+			docs, err := m.c.Database("tx").Collection(col).Find(context.TODO(), bson.M{})
+			if err == nil {
+				lt.Net = col
+
+				for docs.Next(context.Background()) {
+					var tx store.Tx
+					if err = bson.Unmarshal(docs.Current, &tx); err == nil {
+						lt.Txs = append(lt.Txs, tx)
+					}
+				}
+			}
+
+			txs = append(txs, lt)
+		}
+	}
+
+	return txs, nil
+}
+
+// NextNonce allocates atomically the nonce of the next transaction sent from 'addr' in 'net': the greatest of the
+// nonce to be allocated next and 'min'.
+func (m *Mongo) NextNonce(net, addr string, min uint64) (uint64, error) {
+	update := mgo.Pipeline{{{Key: "$set", Value: bson.M{
+		"next": bson.M{"$add": bson.A{bson.M{"$max": bson.A{bson.M{"$ifNull": bson.A{"$next", 0}}, int64(min)}}, 1}},
+	}}}}
+
+	var n struct {
+		Next int64 `bson:"next"`
+	}
+
+	err := m.c.Database("nonce").Collection(net).FindOneAndUpdate(context.Background(), bson.M{"_id": addr}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&n)
+	if err != nil {
+		return 0, fmt.Errorf("could not allocate nonce in db: %w", err)
+	}
+
+	return uint64(n.Next - 1), nil
+}
+
+// ReleaseNonce releases the nonce 'nonce' of 'addr' in 'net' (ie. of a transaction not sent) so it is allocated
+// again, only if it is the last nonce allocated. Nonces allocated later are never reused.
+func (m *Mongo) ReleaseNonce(net, addr string, nonce uint64) error {
+	_, err := m.c.Database("nonce").Collection(net).UpdateOne(context.Background(),
+		bson.M{"_id": addr, "next": int64(nonce + 1)}, bson.M{"$set": bson.M{"next": int64(nonce)}})
+	if err != nil {
+		return fmt.Errorf("could not release nonce in db: %w", err)
+	}
+
+	return nil
+}
+
+// AcquireLease acquires the lease of the explorer of 'net' for 'owner' for 'ttl' if it is not held by another owner or
+// has expired, or renews it if it is held by 'owner'. The fencing token returned is increased when the owner changes.
+func (m *Mongo) AcquireLease(net, owner string, ttl time.Duration) (int64, error) {
+	now := time.Now()
+	free := bson.A{bson.M{"owner": owner}, bson.M{"expires": bson.M{"$lt": now.UnixMilli()}}}
+	update := mgo.Pipeline{{{Key: "$set", Value: bson.M{
+		"token": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$owner", owner}}, "$token", bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$token", 0}}, 1}},
+		}},
+		"owner":   owner,
+		"expires": now.Add(ttl).UnixMilli(),
+	}}}}
+
+	var l struct {
+		Token int64 `bson:"token"`
+	}
+
+	err := m.c.Database("lease").Collection(net).FindOneAndUpdate(context.Background(),
+		bson.M{"_id": "lease", "$or": free}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&l)
+	if mgo.IsDuplicateKeyError(err) { // the lease exists and is held by another owner
+		return 0, store.ErrLeaseHeld
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("could not acquire lease in db: %w", err)
+	}
+
+	return l.Token, nil
+}
+
+// ReleaseLease expires the lease of the explorer of 'net' if it is held by 'owner', so another owner can acquire it.
+func (m *Mongo) ReleaseLease(net, owner string) error {
+	_, err := m.c.Database("lease").Collection(net).UpdateOne(context.Background(),
+		bson.M{"_id": "lease", "owner": owner}, bson.M{"$set": bson.M{"expires": 0}})
+	if err != nil {
+		return fmt.Errorf("could not release lease in db: %w", err)
+	}
+
+	return nil
+}
+
 // LoadExplorer loads from db the NetExplorer type for the indicated blockchain.
 func (m *Mongo) LoadExplorer(net string) (ne store.NetExplorer, err error) {
 	mongoSingleResult := m.c.Database("expl").Collection(net).FindOne(context.TODO(), bson.D{})
@@ -151,25 +314,85 @@ func (m *Mongo) LoadExplorer(net string) (ne store.NetExplorer, err error) {
 	return
 }
 
-// SaveExplorer saves to db the NetExplorer for the indicated blockchain.
+// SaveExplorer saves to db the NetExplorer for the indicated blockchain. If it has a fencing token, it is not saved if
+// it was saved with a greater token and store.ErrFenced is returned.
 func (m *Mongo) SaveExplorer(net string, ne store.NetExplorer) (err error) {
-	_, err = m.c.Database("expl").Collection(net).UpdateOne(context.Background(),
-		bson.D{}, // filter
-		bson.D{ // update
-			{
-				Key: "$set", Value: bson.D{
-					{Key: "block", Value: ne.Block},
-					{Key: "bh", Value: ne.Bh},
-					{Key: "bhi", Value: ne.Bhi},
-					{Key: "map", Value: ne.Map},
-				},
+	col := m.c.Database("expl").Collection(net)
+	update := bson.D{
+		{
+			Key: "$set", Value: bson.D{
+				{Key: "block", Value: ne.Block},
+				{Key: "bh", Value: ne.Bh},
+				{Key: "bhi", Value: ne.Bhi},
+				{Key: "ev", Value: ne.Ev},
+				{Key: "conf", Value: ne.Conf},
+				{Key: "rem", Value: ne.Rem},
+				{Key: "token", Value: ne.Token},
 			},
 		},
-		options.Update().SetUpsert(true))
+		{
+			Key: "$unset", Value: bson.D{
+				{Key: "map", Value: ""},  // addresses saved by older versions, they are kept in db "addr"
+				{Key: "subs", Value: ""}, // likewise
+			},
+		},
+	}
+
+	if ne.Token == 0 {
+		_, err = col.UpdateOne(context.Background(), bson.D{}, update, options.Update().SetUpsert(true))
+
+		return
+	}
+
+	res, err := col.UpdateOne(context.Background(), bson.M{"token": bson.M{"$not": bson.M{"$gt": ne.Token}}}, update)
+	if err != nil || res.MatchedCount == 1 {
+		return
+	}
+	// not saved, either it was saved with a greater token or it has never been saved
+	n, err := col.CountDocuments(context.Background(), bson.D{})
+	if err != nil {
+		return
+	}
+
+	if n > 0 {
+		return store.ErrFenced
+	}
+
+	_, err = col.UpdateOne(context.Background(), bson.D{}, update, options.Update().SetUpsert(true))
 
 	return
 }
 
+// SaveNetwork saves to db the configuration of a network added or removed at runtime.
+func (m *Mongo) SaveNetwork(n store.Network) error {
+	_, err := m.collection("net", "networks", "conf.name").ReplaceOne(context.Background(),
+		bson.M{"conf.name": n.Conf.Name}, n, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("could not save network in db: %w", err)
+	}
+
+	return nil
+}
+
+// GetNetworks returns the networks added or removed at runtime.
+func (m *Mongo) GetNetworks() ([]store.Network, error) {
+	docs, err := m.c.Database("net").Collection("networks").Find(context.Background(), bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting mongo DB object: %w", err)
+	}
+
+	nets := []store.Network{}
+
+	for docs.Next(context.Background()) {
+		var n store.Network
+		if err = bson.Unmarshal(docs.Current, &n); err == nil {
+			nets = append(nets, n)
+		}
+	}
+
+	return nets, nil
+}
+
 // DeleteExplorer deletes from db the NetExplorer for the indicated blockchain.
 func (m *Mongo) DeleteExplorer(net string) (err error) {
 	_, err = m.c.Database("expl").Collection(net).DeleteOne(context.Background(), bson.D{}, options.Delete())
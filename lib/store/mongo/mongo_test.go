@@ -1,6 +1,7 @@
 package mongo
 
 import (
+	"context"
 	"testing"
 
 	"github.com/tarancss/adp/lib/store"
@@ -97,7 +98,6 @@ func TestExplorer(t *testing.T) {
 		Block: 208,
 		Bh:    []string{"first", "second", "third"},
 		Bhi:   0,
-		Map:   nil,
 	}
 
 	if err := m.SaveExplorer("ropsten", ne); err != nil {
@@ -108,3 +108,36 @@ func TestExplorer(t *testing.T) {
 		t.Errorf("LoadExplorer - err:%e, ne2.Bh:%+v", err2, ne2.Bh)
 	}
 }
+
+func TestNonce(t *testing.T) {
+	m, err := New(uri)
+	if err != nil {
+		t.Errorf("err:%e", err)
+
+		return
+	}
+
+	defer m.CloseMongo()
+
+	const addr = "0xcba75f167b03e34b8a572c50273c082401b073ed"
+
+	if err = m.c.Database("nonce").Collection("ropsten").Drop(context.Background()); err != nil {
+		t.Errorf("Drop - err:%e", err)
+	}
+	// the nonces are allocated in order, not lower than the minimum given
+	for _, c := range []struct{ min, nonce uint64 }{{5, 5}, {3, 6}, {10, 10}, {0, 11}} { //nolint:gomnd
+		if n, err := m.NextNonce("ropsten", addr, c.min); err != nil || n != c.nonce {
+			t.Errorf("NextNonce(%d) - nonce:%d expected:%d err:%e", c.min, n, c.nonce, err)
+		}
+	}
+	// only the last nonce allocated is released
+	for _, c := range []struct{ release, nonce uint64 }{{11, 11}, {5, 12}} { //nolint:gomnd
+		if err = m.ReleaseNonce("ropsten", addr, c.release); err != nil {
+			t.Errorf("ReleaseNonce(%d) - err:%e", c.release, err)
+		}
+
+		if n, err := m.NextNonce("ropsten", addr, 0); err != nil || n != c.nonce {
+			t.Errorf("NextNonce after release of %d - nonce:%d expected:%d err:%e", c.release, n, c.nonce, err)
+		}
+	}
+}
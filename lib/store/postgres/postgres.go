@@ -4,6 +4,7 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/lib/pq" //nolint:gci // load the postgres driver that is used by the system
 
@@ -47,6 +48,24 @@ func (p *Postgres) GetAddresses(net []string) (addrs []store.ListenedAddresses,
 	return
 }
 
+func (p *Postgres) AddTx(tx store.Tx, net string) error {
+	println("postgres: AddTx TODO!!!")
+
+	return nil
+}
+
+func (p *Postgres) RemoveTx(tx store.Tx, net string) error {
+	println("postgres: RemoveTx TODO!!!")
+
+	return nil
+}
+
+func (p *Postgres) GetTxs(net []string) (txs []store.ListenedTxs, err error) {
+	println("postgres: GetTxs TODO!!!")
+
+	return
+}
+
 func (p *Postgres) LoadExplorer(net string) (ne store.NetExplorer, err error) {
 	println("postgres: LoadExplorer TODO!!!")
 
@@ -64,3 +83,35 @@ func (p *Postgres) DeleteExplorer(net string) (err error) {
 
 	return
 }
+
+func (p *Postgres) SaveNetwork(n store.Network) (err error) {
+	println("postgres: SaveNetwork TODO!!!")
+
+	return
+}
+
+func (p *Postgres) GetNetworks() (nets []store.Network, err error) {
+	println("postgres: GetNetworks TODO!!!")
+
+	return
+}
+
+func (p *Postgres) NextNonce(net, addr string, min uint64) (nonce uint64, err error) {
+	// no nonce may be allocated twice until nonces are implemented
+	return 0, fmt.Errorf("postgres: NextNonce: %w", store.ErrNotImpl)
+}
+
+func (p *Postgres) ReleaseNonce(net, addr string, nonce uint64) (err error) {
+	return fmt.Errorf("postgres: ReleaseNonce: %w", store.ErrNotImpl)
+}
+
+func (p *Postgres) AcquireLease(net, owner string, ttl time.Duration) (token int64, err error) {
+	// no replica may lead until leases are implemented
+	return 0, fmt.Errorf("postgres: AcquireLease: %w", store.ErrNotImpl)
+}
+
+func (p *Postgres) ReleaseLease(net, owner string) (err error) {
+	println("postgres: ReleaseLease TODO!!!")
+
+	return
+}
@@ -3,6 +3,10 @@ package store
 
 import (
 	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tarancss/adp/lib/config"
 )
 
 // DB defines required methods for wallets and explorers.
@@ -11,13 +15,70 @@ type DB interface {
 	AddAddress(Address, string) ([]byte, error)
 	RemoveAddress(Address, string) error
 	GetAddresses([]string) ([]ListenedAddresses, error)
+	AddTx(Tx, string) error
+	RemoveTx(Tx, string) error
+	GetTxs([]string) ([]ListenedTxs, error)
+	// NextNonce allocates the nonce of the next transaction sent from an address of a network, which is not lower than
+	// the minimum given (ie. the nonce informed by the node). ReleaseNonce releases the nonce given if it was the last
+	// one allocated, so it is allocated again.
+	NextNonce(string, string, uint64) (uint64, error)
+	ReleaseNonce(string, string, uint64) error
 	// methods for explorer service
 	LoadExplorer(string) (NetExplorer, error)
 	SaveExplorer(string, NetExplorer) error
 	DeleteExplorer(string) error
+	// AcquireLease acquires or renews for a time the lease of a network for an owner, returning its fencing token,
+	// which increases every time the lease changes owner. Returns ErrLeaseHeld if another owner holds the lease.
+	AcquireLease(string, string, time.Duration) (int64, error)
+	ReleaseLease(string, string) error
+	// methods for both services
+	SaveNetwork(Network) error
+	GetNetworks() ([]Network, error)
 }
 
 var (
 	ErrAddrNotFound = errors.New("address was not found in store")
+	ErrTxNotFound   = errors.New("transaction was not found in store")
 	ErrDataNotFound = errors.New("data was not found in store")
+	ErrLeaseHeld    = errors.New("lease is held by another owner")
+	ErrFenced       = errors.New("explorer was saved by a newer lease")
+	ErrNotImpl      = errors.New("not implemented by the database")
 )
+
+// Networks returns the blockchain configurations 'bc' updated with the networks added or removed at runtime, saved in
+// 'db', so they survive restarts.
+func Networks(db DB, bc []config.BlockConfig) ([]config.BlockConfig, error) {
+	nets, err := db.GetNetworks()
+	if err != nil {
+		return bc, fmt.Errorf("cannot get networks: %w", err)
+	}
+
+	saved := make(map[string]Network, len(nets))
+	for _, n := range nets {
+		saved[n.Conf.Name] = n
+	}
+
+	r := make([]config.BlockConfig, 0, len(bc)+len(nets))
+
+	for _, c := range bc {
+		if n, ok := saved[c.Name]; ok {
+			delete(saved, c.Name)
+
+			if n.Removed {
+				continue
+			}
+
+			c = n.Conf
+		}
+
+		r = append(r, c)
+	}
+
+	for _, n := range nets {
+		if _, ok := saved[n.Conf.Name]; ok && !n.Removed {
+			r = append(r, n.Conf)
+		}
+	}
+
+	return r, nil
+}
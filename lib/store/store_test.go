@@ -0,0 +1,34 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tarancss/adp/lib/config"
+)
+
+// netDB is a mock database only implementing GetNetworks.
+type netDB struct {
+	DB
+	nets []Network
+}
+
+func (d *netDB) GetNetworks() ([]Network, error) { return d.nets, nil }
+
+// TestNetworks tests the networks added or removed at runtime update the configured ones.
+func TestNetworks(t *testing.T) {
+	bc := []config.BlockConfig{{Name: "ropsten", MaxBlocks: 8}, {Name: "rinkeby", MaxBlocks: 8}, {Name: "mainNet"}}
+	db := &netDB{nets: []Network{
+		{Conf: config.BlockConfig{Name: "rinkeby"}, Removed: true},
+		{Conf: config.BlockConfig{Name: "mainNet", MaxBlocks: 12}},
+		{Conf: config.BlockConfig{Name: "goerli", MaxBlocks: 10}},
+		{Conf: config.BlockConfig{Name: "kovan"}, Removed: true},
+	}}
+
+	r, err := Networks(db, bc)
+	if err != nil || fmt.Sprint(r) != fmt.Sprint([]config.BlockConfig{
+		{Name: "ropsten", MaxBlocks: 8}, {Name: "mainNet", MaxBlocks: 12}, {Name: "goerli", MaxBlocks: 10},
+	}) {
+		t.Errorf("Networks returned %+v err:%e", r, err)
+	}
+}
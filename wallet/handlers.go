@@ -5,16 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/block/ethereum"
 	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/config"
 	"github.com/tarancss/adp/lib/msg"
 	"github.com/tarancss/adp/lib/store"
 	"github.com/tarancss/adp/lib/util"
@@ -32,6 +37,10 @@ type TxReq struct {
 	Tx     types.Trans `json:"tx"`  // transaction details
 }
 
+// TxTimeout is the default number of seconds a transaction is monitored until it is mined, after which the explorer
+// considers it dropped.
+const TxTimeout = 3600
+
 // DryRun is a bool used to control sending transactions to the blockchain. When true, it will not send transactions
 // but just do a dry run.
 var DryRun bool = false //nolint:gochecknoglobals // consider adding this to config
@@ -44,7 +53,17 @@ var (
 	ErrMissingNet = errors.New("undefined blockchain - missing query: ?net=<blockchain>")
 	ErrNoAddr     = errors.New("undefined address - missing in uri")
 	ErrNoHash     = errors.New("a 32-byte hash is required")
+	ErrTimeout    = errors.New("invalid timeout: has to be a number of seconds")
 	ErrNoNet      = errors.New("network not available")
+	ErrSub        = errors.New("invalid subscription: has to be a JSON object")
+	ErrDir        = errors.New(`invalid direction: has to be "in", "out" or "both"`)
+	ErrToken      = errors.New(`invalid token: has to be a 20-byte address or "native"`)
+	ErrMin        = errors.New("invalid minimum value: has to be a 0x-hexadecimal amount")
+	ErrExpiry     = errors.New("invalid expiry: ttl has to be a positive number of seconds and expires a future unix time")
+	ErrCommand    = errors.New(`invalid command: has to be "pause", "resume", "rewind", "rescan", "reload" or "exit"`)
+	ErrBlocks     = errors.New("invalid blocks: rewind requires from > 0 and rescan from > 0 and to >= from")
+	ErrNetConf    = errors.New("invalid network: has to be a JSON blockchain configuration")
+	ErrUTXO       = errors.New("invalid transaction: tokens, data and dynamic fees cannot be sent to this network")
 )
 
 // Response defines the data structure returned to the client making the http request.
@@ -71,7 +90,8 @@ func (w *Wallet) networksHandler(rw http.ResponseWriter, r *http.Request) {
 
 	var res Response
 
-	pl := make([]string, 0, len(w.bc))
+	bc := w.chains()
+	pl := make([]string, 0, len(bc))
 
 	defer func() {
 		// reply to requester accordingly
@@ -91,15 +111,83 @@ func (w *Wallet) networksHandler(rw http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(rw).Encode(&res)
 	}()
 
-	for net := range w.bc {
+	for net := range bc {
 		pl = append(pl, net)
 	}
 }
 
+// addNetworkHandler adds the network configured in the JSON body of the request (see config.BlockConfig), so the wallet
+// and the explorers start serving it. A created status will be replied or an error otherwise.
+func (w *Wallet) addNetworkHandler(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	var res Response
+
+	var conf config.BlockConfig
+
+	defer func() {
+		// reply to requester accordingly
+		if err != nil {
+			res.Error = fmt.Sprintf("%s", err)
+
+			rw.WriteHeader(http.StatusBadRequest)
+		} else {
+			rw.WriteHeader(http.StatusCreated)
+		}
+		// log request
+		log.Printf("httpreq from %v %s net:%s err:%e\n", r.RemoteAddr, r.RequestURI, conf.Name, err)
+		// reply
+		rw.Header().Set("Content-Type", "application/json;charset=utf8")
+		_ = json.NewEncoder(rw).Encode(&res)
+	}()
+
+	if errDec := json.NewDecoder(r.Body).Decode(&conf); errDec != nil {
+		err = ErrNetConf
+
+		return
+	}
+
+	err = w.AddNetwork(conf)
+}
+
+// removeNetworkHandler removes (method DELETE) the network in the uri, so the wallet and the explorers stop serving it.
+// A request accepted status will be replied or an error otherwise.
+func (w *Wallet) removeNetworkHandler(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	var res Response
+
+	defer func() {
+		// reply to requester accordingly
+		if err != nil {
+			res.Error = fmt.Sprintf("%s", err)
+
+			rw.WriteHeader(http.StatusBadRequest)
+		} else {
+			rw.WriteHeader(http.StatusAccepted)
+		}
+		// log request
+		log.Printf("httpreq from %v %s err:%e\n", r.RemoteAddr, r.RequestURI, err)
+		// reply
+		rw.Header().Set("Content-Type", "application/json;charset=utf8")
+		_ = json.NewEncoder(rw).Encode(&res)
+	}()
+
+	if r.Method != http.MethodDelete {
+		err = ErrBadMethod
+
+		return
+	}
+
+	err = w.RemoveNetwork(mux.Vars(r)["net"])
+}
+
 // addrBalance struct used to get balances of addresses from the networks.
 type addrBalance struct {
 	Net string `json:"net"`           // blockchain name
 	Bal string `json:"bal"`           // balance of blockchain currency of address
+	Sym string `json:"sym"`           // symbol of blockchain currency
+	Dec uint8  `json:"dec"`           // decimals of blockchain currency
 	Tok string `json:"tok,omitempty"` // balance of token of address
 }
 
@@ -151,10 +239,39 @@ func (w *Wallet) addrBalHandler(rw http.ResponseWriter, r *http.Request) {
 			// get blockchains
 			nets = r.Form["blk"]
 		}
+		// the error of the last network skipped, replied if no network accepts the address
+		var skipped error
 		// call all the clients
-		for name, client := range w.bc {
+		for name, client := range w.chains() {
 			if len(nets) == 0 || util.In(nets, name) {
-				ethBal, tokBal, err := client.Balance(address, tok)
+				var addr, token string
+
+				var ethBal, tokBal *big.Int
+
+				// the networks whose addresses have another format are skipped, unless they were requested
+				if addr, err = client.Address(address); err != nil {
+					if len(nets) == 0 {
+						skipped, err = err, nil
+
+						continue
+					}
+
+					return
+				}
+
+				if tok != "" {
+					if token, err = client.Address(tok); err != nil {
+						if len(nets) == 0 {
+							skipped, err = err, nil
+
+							continue
+						}
+
+						return
+					}
+				}
+
+				ethBal, tokBal, err = client.Balance(addr, token)
 				if err != nil {
 					if tok != "" && errors.Is(err, ethcli.ErrBadAmt) {
 						// this case happens when the token does not exist for the given blockchain
@@ -167,21 +284,30 @@ func (w *Wallet) addrBalHandler(rw http.ResponseWriter, r *http.Request) {
 					}
 				}
 
-				bals = append(bals, addrBalance{Net: name, Bal: ethBal.String(), Tok: tokBal.String()})
+				native := client.Native()
+				bals = append(bals, addrBalance{Net: name, Bal: ethBal.String(), Sym: native.Symbol,
+					Dec: native.Decimals, Tok: tokBal.String()})
 			}
 		}
+
+		if len(bals) == 0 {
+			err = skipped
+		}
 	} else {
 		err = ErrNoAddr
 	}
 }
 
-// hdAddrHandler replies the HD wallet address requested to the client.
+// hdAddrHandler replies the HD wallet address requested to the client, in EIP-55 checksum form. If the network given
+// in the query derives its addresses from the public key (ie. bitcoin), the address of the network is replied.
 func (w *Wallet) hdAddrHandler(rw http.ResponseWriter, r *http.Request) {
 	var err error
 
 	var res Response
 
-	var addr []byte
+	var addr, key []byte
+
+	var utxo string
 
 	defer func() {
 		// reply to requester accordingly
@@ -191,7 +317,10 @@ func (w *Wallet) hdAddrHandler(rw http.ResponseWriter, r *http.Request) {
 			rw.WriteHeader(http.StatusBadRequest)
 		} else {
 			rw.WriteHeader(http.StatusOK)
-			res.Body = "0x" + hex.EncodeToString(addr)
+			res.Body = ethereum.Checksum("0x" + hex.EncodeToString(addr)) // HD wallet addresses are ethereum addresses
+			if utxo != "" {
+				res.Body = utxo
+			}
 		}
 		// log request and address
 		log.Printf("httpreq from %v %s addr:0x%x err:%e\n", r.RemoteAddr, r.RequestURI, addr, err)
@@ -261,13 +390,108 @@ func (w *Wallet) hdAddrHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// get HD address
-	if addr, _, _, err = w.hd.Address(uint32(wallet), change, uint32(id)); err != nil {
+	if addr, key, _, err = w.hd.Address(uint32(wallet), change, uint32(id)); err != nil {
 		log.Printf("Error obtaining HD wallet address for :%d %d %d\n", wallet, change, id)
+
+		return
+	}
+	// get the address of the key for the network, if any
+	if tmp, ok = r.Form["net"]; ok {
+		b, okN := w.chain(tmp[0])
+		if !okN {
+			err = ErrNoNet
+
+			return
+		}
+
+		if u, okU := b.(block.UTXO); okU {
+			utxo, err = u.KeyAddress(hex.EncodeToString(key))
+		}
+	}
+}
+
+// hexadecimal checks if s is a 0x-hexadecimal string.
+func hexadecimal(s string) bool {
+	if len(s) < 3 || s[:2] != "0x" {
+		return false
+	}
+
+	_, ok := new(big.Int).SetString(s[2:], 16)
+
+	return ok
+}
+
+// readSub reads the subscription record of a listen request from its JSON body, if any. The creation time is set to
+// the current time.
+func readSub(r *http.Request) (*types.Sub, error) {
+	var s types.Sub
+
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Subscription could not be decoded: %e", err)
+
+		return nil, ErrSub
+	}
+
+	if s.Dir != "" && s.Dir != types.DirIn && s.Dir != types.DirOut && s.Dir != types.DirBoth {
+		return nil, ErrDir
+	}
+
+	for i, tok := range s.Tokens {
+		s.Tokens[i] = strings.ToLower(tok) // keep everything in lowercase to avoid issues
+		// 42 = 0x + 20 bytes
+		if s.Tokens[i] != types.TokNative && (len(tok) != 42 || !hexadecimal(tok)) {
+			return nil, ErrToken
+		}
+	}
+
+	if s.Min != "" {
+		if !hexadecimal(s.Min) {
+			return nil, ErrMin
+		}
+	}
+
+	s.Obj, s.Created = "", time.Now().Unix()
+
+	return &s, nil
+}
+
+// readExpiry sets the expiry of a subscription from the 'ttl' (seconds) or 'expires' (unix time) queries of a listen
+// request, and whether an event has to be sent when it expires from the 'notify' query. An expiry in the past is
+// rejected.
+func readExpiry(form url.Values, s *types.Sub) (err error) {
+	now := time.Now().Unix()
+
+	if tmp, ok := form["ttl"]; ok {
+		var ttl int64
+		if ttl, err = strconv.ParseInt(tmp[0], 10, 64); err != nil || ttl <= 0 {
+			return ErrExpiry
+		}
+
+		s.Expires = now + ttl
+	} else if tmp, ok = form["expires"]; ok {
+		if s.Expires, err = strconv.ParseInt(tmp[0], 10, 64); err != nil {
+			return ErrExpiry
+		}
+	}
+
+	if s.Expires != 0 && s.Expires <= now {
+		return ErrExpiry
+	}
+
+	if tmp, ok := form["notify"]; ok {
+		if s.Notify, err = strconv.ParseBool(tmp[0]); err != nil {
+			return ErrExpiry
+		}
 	}
+
+	return nil
 }
 
-// listenHandler sends a wallet request message to the broker to start or stop monitoring an address or account. A
-// request accepted status will be replied or an error otherwise.
+// listenHandler sends a wallet request message to the broker to start or stop monitoring an address or account. When
+// monitoring, a subscription record (owner, label, filters and metadata) can be sent in the JSON body and it will be
+// echoed in the events the address triggers. Only the transactions that pass the filters (direction, tokens and
+// minimum value) trigger events. The subscription expires after the 'ttl' seconds or at the 'expires' unix time given
+// in the query, if any. A request accepted status will be replied or an error otherwise.
 func (w *Wallet) listenHandler(rw http.ResponseWriter, r *http.Request) {
 	var err error
 
@@ -295,7 +519,6 @@ func (w *Wallet) listenHandler(rw http.ResponseWriter, r *http.Request) {
 
 	v := mux.Vars(r)
 	if address, ok := v["address"]; ok {
-		address = strings.ToLower(address) // keep everything in lowercase to avoid issues
 		// get network
 		if err = r.ParseForm(); err != nil {
 			log.Print("Error parsing request URL")
@@ -310,11 +533,25 @@ func (w *Wallet) listenHandler(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		b, okB := w.chain(net[0])
+		if !okB {
+			err = ErrNoNet
+
+			return
+		}
+		// keep the canonical form of the address so it matches the transactions explored
+		if address, err = b.Address(address); err != nil {
+			return
+		}
+
 		var wr msg.WalletReq = msg.WalletReq{Net: net[0], Type: msg.ADDRESS, Obj: address}
 
 		switch r.Method {
 		case "POST":
 			wr.Act = msg.LISTEN
+			if wr.Sub, err = readSub(r); err == nil {
+				err = readExpiry(r.Form, wr.Sub)
+			}
 		case "DELETE":
 			wr.Act = msg.UNLISTEN
 		default:
@@ -329,8 +566,174 @@ func (w *Wallet) listenHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// getAddrHandler replies the client with the addresses being monitored for the specified network. If no network is
-// queried, addresses from all the networks are returned.
+// listenTxHandler sends a wallet request message to the broker to start or stop monitoring a transaction. The explorer
+// will send events when the transaction is mined, confirmed or failed, and when it is dropped if it has not been mined
+// after the timeout (in seconds) given in the query. A subscription record can be sent in the JSON body like for
+// addresses. A request accepted status will be replied or an error otherwise.
+func (w *Wallet) listenTxHandler(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	var res Response
+
+	defer func() {
+		// reply to requester accordingly
+		if err != nil {
+			res.Error = fmt.Sprintf("%s", err)
+
+			rw.WriteHeader(http.StatusBadRequest)
+		} else {
+			rw.WriteHeader(http.StatusAccepted)
+		}
+		// log request
+		log.Printf("httpreq from %v %s err:%e\n", r.RemoteAddr, r.RequestURI, err)
+		// reply
+		rw.Header().Set("Content-Type", "application/json;charset=utf8")
+		_ = json.NewEncoder(rw).Encode(&res)
+	}()
+
+	v := mux.Vars(r)
+
+	hash, ok := v["hash"]
+	if !ok || !txHash(hash) {
+		err = ErrNoHash
+
+		return
+	}
+
+	hash = strings.ToLower(hash)
+	// get network
+	if err = r.ParseForm(); err != nil {
+		log.Print("Error parsing request URL")
+
+		return
+	}
+
+	net, okN := r.Form["net"]
+	if !okN || len(net) != 1 { // we only allow 1 net per request
+		err = ErrMissingNet
+
+		return
+	}
+
+	if _, okB := w.chain(net[0]); !okB {
+		err = ErrNoNet
+
+		return
+	}
+
+	var wr msg.WalletReq = msg.WalletReq{Net: net[0], Type: msg.TX, Obj: hash}
+
+	switch r.Method {
+	case "POST":
+		wr.Act = msg.LISTEN
+		wr.Timeout = TxTimeout
+		// get timeout
+		if tmp, okT := r.Form["timeout"]; okT {
+			if wr.Timeout, err = strconv.ParseInt(tmp[0], 10, 64); err != nil || wr.Timeout < 0 {
+				log.Printf("Timeout %s could not be decoded into a valid number of seconds", tmp[0])
+
+				err = ErrTimeout
+
+				return
+			}
+		}
+
+		if wr.Sub, err = readSub(r); err != nil {
+			return
+		}
+	case "DELETE":
+		wr.Act = msg.UNLISTEN
+	default:
+		err = ErrBadMethod
+
+		return
+	}
+	// send message to broker
+	err = w.mb.SendRequest(net[0], wr)
+}
+
+// controlHandler sends a control command to the broker for the explorer of the network given in the query. Commands
+// rewind and rescan take the blocks in queries from and to. A request accepted status will be replied or an error
+// otherwise.
+func (w *Wallet) controlHandler(rw http.ResponseWriter, r *http.Request) {
+	var err error
+
+	var res Response
+
+	defer func() {
+		// reply to requester accordingly
+		if err != nil {
+			res.Error = fmt.Sprintf("%s", err)
+
+			rw.WriteHeader(http.StatusBadRequest)
+		} else {
+			rw.WriteHeader(http.StatusAccepted)
+		}
+		// log request
+		log.Printf("httpreq from %v %s err:%e\n", r.RemoteAddr, r.RequestURI, err)
+		// reply
+		rw.Header().Set("Content-Type", "application/json;charset=utf8")
+		_ = json.NewEncoder(rw).Encode(&res)
+	}()
+	// get network
+	if err = r.ParseForm(); err != nil {
+		log.Print("Error parsing request URL")
+
+		return
+	}
+
+	net, okN := r.Form["net"]
+	if !okN || len(net) != 1 { // we only allow 1 net per request
+		err = ErrMissingNet
+
+		return
+	}
+
+	if _, ok := w.chain(net[0]); !ok {
+		err = ErrNoNet
+
+		return
+	}
+
+	wr := msg.WalletReq{Net: net[0], Type: msg.CTRL, Obj: mux.Vars(r)["cmd"]}
+
+	for q, b := range map[string]*uint64{"from": &wr.From, "to": &wr.To} {
+		if tmp := r.Form.Get(q); tmp != "" {
+			if *b, err = strconv.ParseUint(tmp, 10, 64); err != nil {
+				err = ErrBlocks
+
+				return
+			}
+		}
+	}
+
+	switch wr.Obj {
+	case msg.CmdPause, msg.CmdResume, msg.CmdReload:
+	case msg.CmdRewind:
+		if wr.From == 0 {
+			err = ErrBlocks
+
+			return
+		}
+	case msg.CmdRescan:
+		if wr.From == 0 || wr.To < wr.From {
+			err = ErrBlocks
+
+			return
+		}
+	case msg.CmdExit:
+		wr.Type = msg.EXIT
+	default:
+		err = ErrCommand
+
+		return
+	}
+	// send message to broker
+	err = w.mb.SendRequest(net[0], wr)
+}
+
+// getAddrHandler replies the client with the addresses being monitored for the specified network, in the form shown
+// to clients (ie. EIP-55 checksum). If no network is queried, addresses from all the networks are returned.
 func (w *Wallet) getAddrHandler(rw http.ResponseWriter, r *http.Request) {
 	var err error
 
@@ -370,11 +773,22 @@ func (w *Wallet) getAddrHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// get addresses from DB
-	addrs, err = w.db.GetAddresses(net) // ideally, this should be requested to the explorer!!
+	if addrs, err = w.db.GetAddresses(net); err != nil { // ideally, this should be requested to the explorer!!
+		return
+	}
+	// reply addresses in the form shown to clients
+	for i := range addrs {
+		if b, okB := w.chain(addrs[i].Net); okB {
+			for j := range addrs[i].Addr {
+				addrs[i].Addr[j].Addr = b.Checksum(addrs[i].Addr[j].Addr)
+			}
+		}
+	}
 }
 
 // sendHandler creates a send ether or ERC20 token transaction and sends it to the appropriate network for execution.
-// A response is given to the client with the transaction hash or error.
+// The destination and token addresses are validated. A response is given to the client with the transaction hash or
+// error.
 func (w *Wallet) sendHandler(rw http.ResponseWriter, r *http.Request) {
 	var err error
 
@@ -424,26 +838,52 @@ func (w *Wallet) sendHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// send tx ...
-	b, ok := w.bc[txReq.Net]
+	b, ok := w.chain(txReq.Net)
 	if !ok {
 		err = ErrNoNet
 
 		return
 	}
 
+	if txReq.Tx.To != "" {
+		if txReq.Tx.To, err = b.Address(txReq.Tx.To); err != nil {
+			return
+		}
+	}
+
+	if txReq.Tx.Token != "" {
+		if txReq.Tx.Token, err = b.Address(txReq.Tx.Token); err != nil {
+			return
+		}
+	}
+
 	if len(txReq.Tx.Data) > 0 {
 		data = []byte(txReq.Tx.Data)
 	} else {
 		data = nil
 	}
 
-	fee, hash, err = b.Send("0x"+hex.EncodeToString(addr), txReq.Tx.To, txReq.Tx.Token, txReq.Tx.Value,
-		data, hex.EncodeToString(key), txReq.Tx.Price, DryRun)
-	// load return values
-	txReq.Tx.Hash = "0x" + hex.EncodeToString(hash)
-	txReq.Tx.From = "0x" + hex.EncodeToString(addr)
+	if u, ok := b.(block.UTXO); ok {
+		fee, hash, err = w.sendChange(u, &txReq, key)
+	} else {
+		fees := types.Fees{Price: txReq.Tx.Price, MaxFee: txReq.Tx.MaxFee, MaxTip: txReq.Tx.MaxTip}
+		if a, ok := b.(block.Account); ok {
+			fee, hash, err = w.nc.send(a, txReq.Net, "0x"+hex.EncodeToString(addr), txReq.Tx.To, txReq.Tx.Token,
+				txReq.Tx.Value, data, hex.EncodeToString(key), &fees, DryRun)
+		} else {
+			fee, hash, err = b.Send("0x"+hex.EncodeToString(addr), txReq.Tx.To, txReq.Tx.Token, txReq.Tx.Value,
+				data, hex.EncodeToString(key), &fees, DryRun)
+		}
+		txReq.Tx.Price, txReq.Tx.MaxFee, txReq.Tx.MaxTip = fees.Price, fees.MaxFee, fees.MaxTip
+		// load return values
+		txReq.Tx.Hash = "0x" + hex.EncodeToString(hash)
+		txReq.Tx.From = "0x" + hex.EncodeToString(addr)
+	}
+
 	txReq.Tx.Fee = fee.Uint64()
 
+	checksum(b, &txReq.Tx)
+
 	if err == nil {
 		txReq.Tx.Status = ethcli.TrxPending
 	} else {
@@ -452,6 +892,45 @@ func (w *Wallet) sendHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// sendChange sends a transaction to a UTXO chain 'u' from the address of the HD wallet 'key', sending the change to
+// the address with the same id of the wallet's change branch. The sender and transaction id are set in the request.
+func (w *Wallet) sendChange(u block.UTXO, txReq *TxReq, key []byte) (fee *big.Int, hash []byte, err error) {
+	fee = new(big.Int)
+
+	if txReq.Tx.Token != "" || txReq.Tx.Data != "" || txReq.Tx.MaxFee != 0 || txReq.Tx.MaxTip != 0 {
+		return fee, nil, ErrUTXO
+	}
+
+	_, changeKey, _, err := w.hd.Address(txReq.Wallet, hd.Change, txReq.ID)
+	if err != nil {
+		return fee, nil, fmt.Errorf("cannot obtain HD wallet change address: %w", err)
+	}
+
+	if txReq.Tx.From, err = u.KeyAddress(hex.EncodeToString(key)); err != nil {
+		return fee, nil, err
+	}
+
+	change, err := u.KeyAddress(hex.EncodeToString(changeKey))
+	if err != nil {
+		return fee, nil, err
+	}
+
+	fee, hash, err = u.SendChange(txReq.Tx.From, txReq.Tx.To, change, txReq.Tx.Value, hex.EncodeToString(key),
+		txReq.Tx.Price, DryRun)
+	txReq.Tx.Hash = hex.EncodeToString(hash)
+
+	return fee, hash, err
+}
+
+// txHash checks if s is a 32-byte transaction hash: 0x-hexadecimal, or hexadecimal for UTXO chains (ie. bitcoin).
+func txHash(s string) bool {
+	if len(s) == 66 && hexadecimal(s) { // 66 = 0x + 32 bytes
+		return true
+	}
+
+	return len(s) == 64 && hexadecimal("0x"+s)
+}
+
 // txHandler gets the details of the specified transaction and network and replies it to the client request.
 func (w *Wallet) txHandler(rw http.ResponseWriter, r *http.Request) {
 	var err error
@@ -485,12 +964,12 @@ func (w *Wallet) txHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	v := mux.Vars(r)
-	if hash, ok := v["hash"]; ok && len(hash) == 66 { // 42 = 0x + 32 bytes
+	if hash, ok := v["hash"]; ok && txHash(hash) {
 		// get network
 		var b block.Chain
 
 		if tmp, ok := r.Form["net"]; ok {
-			if b, ok = w.bc[tmp[0]]; !ok {
+			if b, ok = w.chain(tmp[0]); !ok {
 				log.Printf("Blockchain client for network %s could not be found", tmp[0])
 
 				err = ErrNoNet
@@ -503,8 +982,19 @@ func (w *Wallet) txHandler(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		tx, err = b.Get(hash)
+		if tx, err = b.Get(hash); err == nil {
+			checksum(b, tx)
+		}
 	} else {
 		err = ErrNoHash
 	}
 }
+
+// checksum sets the addresses of a transaction to the form shown to clients by the blockchain 'b'.
+func checksum(b block.Chain, tx *types.Trans) {
+	for _, a := range []*string{&tx.From, &tx.To, &tx.Token, &tx.Contract} {
+		if *a != "" {
+			*a = b.Checksum(*a)
+		}
+	}
+}
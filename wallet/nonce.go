@@ -0,0 +1,124 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/store"
+)
+
+// nonces allocates the nonces of the transactions sent from the addresses of the HD wallet, so the requests sending
+// concurrently from the same address do not use the same nonce. The nonces are allocated atomically by the database,
+// so they are also safe across wallet instances, and the transactions from an address are sent one at a time by each
+// instance. The nonce of an address is resynchronized with the node the first time the address sends a transaction
+// after startup, and after a transaction is not sent (ie. rejected by the node because its nonce was used), never
+// allocating a nonce lower than the ones allocated and not sent yet. The nonce of a transaction not sent is released
+// so it is allocated again, unless a later nonce has been allocated meanwhile by another instance.
+type nonces struct {
+	db     store.DB
+	l      sync.Mutex
+	locks  map[string]*sync.Mutex // locks of the addresses by network and address
+	synced map[string]bool        // addresses resynchronized with the node by network and address
+}
+
+// newNonces returns a nonce allocator saving the nonces in 'db'.
+func newNonces(db store.DB) *nonces {
+	return &nonces{db: db, locks: make(map[string]*sync.Mutex), synced: make(map[string]bool)}
+}
+
+// lock locks the address 'addr' of network 'net' so its transactions are sent one at a time, returning the function
+// that unlocks it.
+func (n *nonces) lock(net, addr string) func() {
+	n.l.Lock()
+
+	l, ok := n.locks[net+"/"+addr]
+	if !ok {
+		l = &sync.Mutex{}
+		n.locks[net+"/"+addr] = l
+	}
+	n.l.Unlock()
+
+	l.Lock()
+
+	return l.Unlock
+}
+
+// next allocates the nonce of the next transaction sent from 'addr' in network 'net' of chain 'a', resynchronizing
+// it with the node first if required. Must be called with the address locked.
+func (n *nonces) next(a block.Account, net, addr string) (uint64, error) {
+	n.l.Lock()
+	synced := n.synced[net+"/"+addr]
+	n.l.Unlock()
+
+	if synced {
+		return n.db.NextNonce(net, addr, 0)
+	}
+
+	min, err := a.Nonce(addr)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get nonce of %s: %w", addr, err)
+	}
+
+	nonce, err := n.db.NextNonce(net, addr, min)
+	if err != nil {
+		return 0, err
+	}
+
+	n.l.Lock()
+	n.synced[net+"/"+addr] = true
+	n.l.Unlock()
+
+	return nonce, nil
+}
+
+// resync releases the nonce 'nonce' of 'addr' in network 'net', of a transaction not sent, and makes it be
+// resynchronized with the node before it is allocated next.
+func (n *nonces) resync(net, addr string, nonce uint64) {
+	n.l.Lock()
+	delete(n.synced, net+"/"+addr)
+	n.l.Unlock()
+
+	if err := n.db.ReleaseNonce(net, addr, nonce); err != nil {
+		log.Printf("[%s] Cannot release nonce %d of %s, err:%e", net, nonce, addr, err)
+	}
+}
+
+// send sends a transaction from 'from' to chain 'a' with a nonce allocated by the wallet. If the node rejects the
+// transaction because its nonce has been used (ie. by a transaction sent by another wallet), the nonce is
+// resynchronized with the node and the transaction is sent again once. If the transaction is not sent, its nonce is
+// released so it is not skipped. Without a database, or if it does not allocate nonces, the transaction is sent with
+// the pending nonce informed by the node.
+func (n *nonces) send(a block.Account, net, from, to, token, amount string, data []byte, key string,
+	fees *types.Fees, dryRun bool) (fee *big.Int, hash []byte, err error) {
+	defer n.lock(net, from)()
+
+	if n.db == nil {
+		return a.Send(from, to, token, amount, data, key, fees, dryRun)
+	}
+
+	for retry := true; ; retry = false {
+		var nonce uint64
+
+		if nonce, err = n.next(a, net, from); errors.Is(err, store.ErrNotImpl) {
+			return a.Send(from, to, token, amount, data, key, fees, dryRun)
+		} else if err != nil {
+			return new(big.Int), nil, err
+		}
+
+		fee, hash, err = a.SendNonce(from, to, token, amount, data, key, fees, nonce, dryRun)
+		if err != nil || dryRun {
+			n.resync(net, from, nonce)
+		}
+
+		if !retry || !errors.Is(err, types.ErrNonce) {
+			return fee, hash, err
+		}
+
+		log.Printf("[%s] Nonce %d of %s has been used, resynchronizing with the node, err:%e", net, nonce, from, err)
+	}
+}
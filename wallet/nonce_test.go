@@ -0,0 +1,174 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/block/types"
+	"github.com/tarancss/adp/lib/store"
+)
+
+// nonceDB is a mock database only implementing the nonces.
+type nonceDB struct {
+	store.DB
+	l    sync.Mutex
+	next map[string]uint64
+}
+
+func (d *nonceDB) NextNonce(net, addr string, min uint64) (uint64, error) {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	n := d.next[net+addr]
+	if n < min {
+		n = min
+	}
+
+	d.next[net+addr] = n + 1
+
+	return n, nil
+}
+
+func (d *nonceDB) ReleaseNonce(net, addr string, nonce uint64) error {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	if d.next[net+addr] == nonce+1 {
+		d.next[net+addr] = nonce
+	}
+
+	return nil
+}
+
+// implDB is a mock database not implementing the nonces.
+type implDB struct {
+	store.DB
+}
+
+func (d *implDB) NextNonce(net, addr string, min uint64) (uint64, error) {
+	return 0, fmt.Errorf("postgres: NextNonce: %w", store.ErrNotImpl)
+}
+
+// nonceChain is a mock account chain whose node has seen the transactions up to nonce 'count', rejecting the lower
+// nonces, and failing the transactions of 'fail' value. The transactions of 'busy' value fail after another wallet
+// has allocated a nonce from 'db'.
+type nonceChain struct {
+	block.Chain
+	l     sync.Mutex
+	count uint64
+	sent  []uint64 // nonces of the transactions sent
+	fail  string
+	busy  string
+	db    *nonceDB
+}
+
+func (c *nonceChain) Nonce(addr string) (uint64, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.count, nil
+}
+
+func (c *nonceChain) Send(fromAddress, toAddress, token, amount string, data []byte, key string, fees *types.Fees,
+	dryRun bool) (*big.Int, []byte, error) {
+	nonce, _ := c.Nonce(fromAddress)
+
+	return c.SendNonce(fromAddress, toAddress, token, amount, data, key, fees, nonce, dryRun)
+}
+
+func (c *nonceChain) SendNonce(fromAddress, toAddress, token, amount string, data []byte, key string,
+	fees *types.Fees, nonce uint64, dryRun bool) (*big.Int, []byte, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if nonce < c.count {
+		return new(big.Int), nil, types.ErrNonce
+	}
+
+	if amount == c.busy {
+		_, _ = c.db.NextNonce("ropsten", fromAddress, 0)
+	}
+
+	if amount == c.fail || amount == c.busy {
+		return new(big.Int), nil, errors.New("insufficient funds for gas * price + value")
+	}
+
+	c.sent = append(c.sent, nonce)
+	c.count = nonce + 1
+
+	return new(big.Int), []byte{byte(nonce)}, nil
+}
+
+// TestNonces tests concurrent sends from the same address use consecutive nonces, which are resynchronized with the
+// node when used by other wallets or not sent.
+func TestNonces(t *testing.T) {
+	const from = "0xcba75f167b03e34b8a572c50273c082401b073ed"
+
+	db := &nonceDB{next: make(map[string]uint64)}
+	c := &nonceChain{count: 3, fail: "0xbad", busy: "0xbusy", db: db}
+	n := newNonces(db)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, _, err := n.send(c, "ropsten", from, "", "", "0x1", nil, "", &types.Fees{}, false); err != nil {
+				t.Errorf("send err:%e", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	sort.Slice(c.sent, func(i, j int) bool { return c.sent[i] < c.sent[j] })
+
+	for i, nonce := range c.sent {
+		if nonce != uint64(i)+3 {
+			t.Fatalf("nonces sent:%v", c.sent)
+		}
+	}
+	// another wallet sends 5 transactions, then the nonce is resynchronized and the transaction sent again
+	c.count += 5
+
+	if _, hash, err := n.send(c, "ropsten", from, "", "", "0x1", nil, "", &types.Fees{}, false); err != nil ||
+		hash[0] != 28 {
+		t.Errorf("send after nonces used hash:%x err:%e", hash, err)
+	}
+	// a transaction not sent does not leave a gap
+	if _, _, err := n.send(c, "ropsten", from, "", "", "0xbad", nil, "", &types.Fees{}, false); err == nil {
+		t.Errorf("send should have failed")
+	}
+
+	if _, hash, err := n.send(c, "ropsten", from, "", "", "0x1", nil, "", &types.Fees{}, false); err != nil ||
+		hash[0] != 29 {
+		t.Errorf("send after failure hash:%x err:%e", hash, err)
+	}
+	// the nonce of a transaction not sent is not released once another wallet has allocated the next one
+	if _, _, err := n.send(c, "ropsten", from, "", "", "0xbusy", nil, "", &types.Fees{}, false); err == nil {
+		t.Errorf("send should have failed")
+	}
+
+	if _, hash, err := n.send(c, "ropsten", from, "", "", "0x1", nil, "", &types.Fees{}, false); err != nil ||
+		hash[0] != 32 {
+		t.Errorf("send after nonce allocated by another wallet hash:%x err:%e", hash, err)
+	}
+	// without a database, or if it does not allocate nonces, the nonce is the one informed by the node
+	for _, db := range []store.DB{nil, &implDB{}} {
+		c.count = 40
+		n = newNonces(db)
+
+		if _, hash, err := n.send(c, "ropsten", from, "", "", "0x1", nil, "", &types.Fees{}, false); err != nil ||
+			hash[0] != 40 {
+			t.Errorf("send with db %T hash:%x err:%e", db, hash, err)
+		}
+	}
+}
@@ -20,12 +20,16 @@ func (w *Wallet) Init(endpoint, port, sslPort, sslCert, sslKey string) string {
 	r := mux.NewRouter()
 	r.HandleFunc("/", w.homeHandler)
 	r.HandleFunc("/networks", w.networksHandler).Methods("GET")         // get all available blockchains
+	r.HandleFunc("/networks", w.addNetworkHandler).Methods("POST")      // add a blockchain
+	r.HandleFunc("/networks/{net}", w.removeNetworkHandler)             // remove a blockchain
 	r.HandleFunc("/address/{address}", w.addrBalHandler).Methods("GET") // get address balance
 	r.HandleFunc("/address", w.hdAddrHandler).Methods("GET")            // get address from HD wallet
 	r.HandleFunc("/listen/{address}", w.listenHandler)                  // listen events related to the address
+	r.HandleFunc("/listen/tx/{hash}", w.listenTxHandler)                // listen events related to the transaction
 	r.HandleFunc("/listen", w.getAddrHandler).Methods("GET")            // Get listened addresses
 	r.HandleFunc("/send", w.sendHandler).Methods("POST")                // send a transaction
 	r.HandleFunc("/tx/{hash}", w.txHandler).Methods("GET")              // get transaction details
+	r.HandleFunc("/control/{cmd}", w.controlHandler).Methods("POST")    // send a control command to the explorer
 	http.Handle("/", r)
 
 	// setup shutdown channel
@@ -6,12 +6,14 @@ package wallet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sync"
 
 	"github.com/tarancss/adp/lib/block"
+	"github.com/tarancss/adp/lib/config"
 	"github.com/tarancss/adp/lib/msg"
 	"github.com/tarancss/adp/lib/store"
 	"github.com/tarancss/adp/lib/store/db"
@@ -24,8 +26,12 @@ type Wallet struct {
 	dbtype string
 	db     store.DB // db connection
 
+	bl sync.RWMutex           // bl is a mutex to ensure concurrent access to bc, networks can be added or removed
 	bc map[string]block.Chain // blockchain clients
+	el sync.Mutex             // el is a mutex to ensure concurrent access to ev
+	ev map[string]bool        // networks whose events are being consumed
 	hd *hd.HdWallet           // HD wallet
+	nc *nonces                // nonces of the transactions sent
 	mb msg.MsgBroker
 	s  *http.Server  // http server
 	ss *http.Server  // https server
@@ -39,10 +45,117 @@ func New(dbtype string, dbConn store.DB, mb msg.MsgBroker, bc map[string]block.C
 		db:     dbConn,
 		mb:     mb,
 		bc:     bc,
+		ev:     make(map[string]bool),
 		hd:     hdw,
+		nc:     newNonces(dbConn),
 	}
 }
 
+// chain returns the client of blockchain named 'net', 'ok' is false if the network is not available.
+func (w *Wallet) chain(net string) (c block.Chain, ok bool) {
+	w.bl.RLock()
+	defer w.bl.RUnlock()
+
+	c, ok = w.bc[net]
+
+	return
+}
+
+// chains returns the clients of the blockchains available by network.
+func (w *Wallet) chains() map[string]block.Chain {
+	w.bl.RLock()
+	defer w.bl.RUnlock()
+
+	bc := make(map[string]block.Chain, len(w.bc))
+	for net, c := range w.bc {
+		bc[net] = c
+	}
+
+	return bc
+}
+
+// Errors returned adding or removing networks.
+var (
+	ErrNetExists = errors.New("network already available")
+	ErrNetName   = msg.ErrNetName
+)
+
+// AddNetwork connects to the blockchain configured in 'conf' and starts serving it. The network is saved to DB so it is
+// served after a restart (see store.Networks), and a request is sent to the explorers to start exploring it. The node,
+// DB and message broker are called before the network is added, so the requests to the other networks are not delayed.
+func (w *Wallet) AddNetwork(conf config.BlockConfig) error {
+	if !msg.NetName(conf.Name) {
+		return ErrNetName
+	}
+
+	if _, ok := w.chain(conf.Name); ok {
+		return ErrNetExists
+	}
+
+	c, err := block.New(conf)
+	if err != nil {
+		return fmt.Errorf("cannot add network: %w", err)
+	}
+
+	if w.db != nil {
+		if err = w.db.SaveNetwork(store.Network{Conf: conf}); err != nil {
+			c.Close()
+
+			return fmt.Errorf("cannot save network: %w", err)
+		}
+	}
+
+	err = w.mb.SendRequest(msg.NETWORKS, msg.WalletReq{Net: conf.Name, Type: msg.NET, Obj: conf.Name, Act: msg.LISTEN,
+		Conf: &conf})
+	if err != nil {
+		c.Close()
+
+		return fmt.Errorf("cannot send network request: %w", err)
+	}
+
+	w.bl.Lock()
+	_, ok := w.bc[conf.Name]
+	if !ok {
+		w.bc[conf.Name] = c
+	}
+	w.bl.Unlock()
+
+	if ok { // added meanwhile by another request
+		c.Close()
+
+		return ErrNetExists
+	}
+
+	log.Printf("[%s] Network added", conf.Name)
+
+	return w.manageEvents(conf.Name)
+}
+
+// RemoveNetwork stops serving blockchain named 'net' and closes its client. The network is saved to DB as removed so
+// it is not served after a restart, and a request is sent to the explorers to stop exploring it.
+func (w *Wallet) RemoveNetwork(net string) error {
+	w.bl.Lock()
+	defer w.bl.Unlock()
+
+	c, ok := w.bc[net]
+	if !ok {
+		return ErrNoNet
+	}
+
+	delete(w.bc, net)
+	c.Close()
+
+	log.Printf("[%s] Network removed", net)
+
+	if w.db != nil {
+		if err := w.db.SaveNetwork(store.Network{Conf: config.BlockConfig{Name: net}, Removed: true}); err != nil {
+			return fmt.Errorf("cannot save network: %w", err)
+		}
+	}
+
+	return w.mb.SendRequest(msg.NETWORKS, msg.WalletReq{Net: net, Type: msg.NET, Obj: net, Act: msg.UNLISTEN})
+}
+
 // Stop shuts down the http servers implementing the RESTful API and closes gracefully the connections to message
 // broker, monitoring service and database.
 func (w *Wallet) Stop() {
@@ -78,43 +191,63 @@ func (w *Wallet) Stop() {
 // each connected blockchain, two channels are opened, one for transaction events, and one for errors. A go routine is
 // triggered reading for either channel to manage the events/errors.
 func (w *Wallet) ManageEvents() error {
+	w.bl.Lock()
+	defer w.bl.Unlock()
 	// for each chain establish a process to read events from the broker queues
 	for net := range w.bc {
-		// open events channel
-		var mut *sync.Mutex = new(sync.Mutex)
+		if err := w.manageEvents(net); err != nil {
+			return err
+		}
+	}
 
-		mut.Lock()
+	return nil
+}
 
-		eveCh, errCh, err := w.mb.GetEvents(net, mut)
-		if err != nil {
-			return fmt.Errorf("cannot get events from broker for net %s: %w", net, err)
-		}
-		// launch request channel reader
-		go func(netName string) {
-			for {
-				select {
-				case eve, ok := (<-eveCh):
-					if !ok {
-						log.Printf("[%s] Stop listening to events channel", netName)
+// manageEvents starts a go routine to consume the events of blockchain named 'net', unless they are already being
+// consumed (ie. the network was removed and added again). Must be called with bl held.
+func (w *Wallet) manageEvents(net string) error {
+	w.el.Lock()
+	defer w.el.Unlock()
+
+	if w.ev[net] {
+		return nil
+	}
+	// open events channel
+	var mut *sync.Mutex = new(sync.Mutex)
 
-						break
-					}
+	mut.Lock()
 
-					log.Printf("[%s] Received event %+v", netName, eve) // we just log it to console!! XXX
+	eveCh, errCh, err := w.mb.GetEvents(net, mut)
+	if err != nil {
+		return fmt.Errorf("cannot get events from broker for net %s: %w", net, err)
+	}
+
+	w.ev[net] = true
+	// launch request channel reader
+	go func(netName string) {
+		for {
+			select {
+			case eve, ok := (<-eveCh):
+				if !ok {
+					log.Printf("[%s] Stop listening to events channel", netName)
+
+					break
+				}
 
-					mut.Unlock()
-				case e, ok := (<-errCh):
-					if !ok {
-						log.Printf("[%s] Stop listening to events channel", netName)
+				log.Printf("[%s] Received event %+v", netName, eve) // we just log it to console!! XXX
 
-						break
-					}
+				mut.Unlock()
+			case e, ok := (<-errCh):
+				if !ok {
+					log.Printf("[%s] Stop listening to events channel", netName)
 
-					log.Printf("[%s] Received error %+v", netName, e)
+					break
 				}
+
+				log.Printf("[%s] Received error %+v", netName, e)
 			}
-		}(net)
-	}
+		}
+	}(net)
 
 	return nil
 }
@@ -92,14 +92,21 @@ func TestAPI(t *testing.T) {
 		{"homePage_1", http.MethodGet, "http://localhost:3030", nil, nil, http.StatusOK, "", "Hello, this is your multi-blockchain adaptor!"},
 		{"homePage_2", http.MethodGet, "http://localhost:3030/", nil, nil, http.StatusOK, "", "Hello, this is your multi-blockchain adaptor!"},
 		{"homePage_3", http.MethodPost, "http://localhost:3030/", nil, nil, http.StatusOK, "", "Hello, this is your multi-blockchain adaptor!"},
-		{"networks_0", http.MethodPost, "http://localhost:3030/networks", nil, nil, 405, "", []string{}},
+		{"networks_0", http.MethodPut, "http://localhost:3030/networks", nil, nil, 405, "", []string{}},
 		{"networks_1", http.MethodGet, "http://localhost:3030/networks", nil, nil, 200, "", []string{"ropsten"}},
+		{"networks_2", http.MethodPost, "http://localhost:3030/networks", "ropsten", nil, http.StatusBadRequest, ErrNetConf.Error(), ""},
+		{"networks_3", http.MethodPost, "http://localhost:3030/networks", config.BlockConfig{Name: "main.net", MaxBlocks: 8}, nil, http.StatusBadRequest, ErrNetName.Error(), ""},
+		{"networks_4", http.MethodPost, "http://localhost:3030/networks", config.BlockConfig{Name: "ropsten", MaxBlocks: 8}, nil, http.StatusBadRequest, ErrNetExists.Error(), ""},
+		{"networks_5", http.MethodPost, "http://localhost:3030/networks", config.BlockConfig{Name: "mainNet", MaxBlocks: 8, Confirmations: 8}, nil, http.StatusBadRequest, "cannot add network: confirmations have to be lower than maxBlocks: mainNet", ""},
+		{"networks_6", http.MethodDelete, "http://localhost:3030/networks/rinkeby", nil, nil, http.StatusBadRequest, ErrNoNet.Error(), ""},
+		{"networks_7", http.MethodGet, "http://localhost:3030/networks/ropsten", nil, nil, http.StatusBadRequest, ErrBadMethod.Error(), ""},
 		{"addrbal_0", http.MethodPost, "http://localhost:3030/address/0x?tok=0x", nil, nil, 405, "", ""},
-		{"addrbal_1", http.MethodGet, "http://localhost:3030/address/0x", nil, nil, 200, "", []addrBalance{{Net: "ropsten", Bal: "0", Tok: "0"}}},
-		{"addrbal_2", http.MethodGet, "http://localhost:3030/address/0x?tok=0x", nil, nil, 200, "", []addrBalance{{Net: "ropsten", Bal: "0", Tok: "0"}}},
+		{"addrbal_1", http.MethodGet, "http://localhost:3030/address/0x", nil, nil, http.StatusBadRequest, "invalid address: 0x has to be 0x followed by 40 hexadecimal digits", ""},
+		{"addrbal_2", http.MethodGet, "http://localhost:3030/address/0xcba75F167B03e34B8a572c50273C082401b073Ed?tok=0x", nil, nil, http.StatusBadRequest, "invalid address: 0x has to be 0x followed by 40 hexadecimal digits", ""},
+		{"addrbal_2a", http.MethodGet, "http://localhost:3030/address/0xCba75F167B03e34B8a572c50273C082401b073Ed", nil, nil, http.StatusBadRequest, "invalid address checksum: 0xCba75F167B03e34B8a572c50273C082401b073Ed", ""},
 		{"addrbal_3", http.MethodGet, "http://localhost:3030/address/0xcba75F167B03e34B8a572c50273C082401b073Ed", nil, nil, 200, "", []addrBalance{{Net: "ropsten", Bal: "1615796230433485760", Tok: "0"}}},
 		{"addrbal_4", http.MethodGet, "http://localhost:3030/address/0xcba75F167B03e34B8a572c50273C082401b073Ed?tok=0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f", nil, nil, 200, "", []addrBalance{{Net: "ropsten", Bal: "1615796230433485760", Tok: "751000000000000000"}}},
-		{"address_0", http.MethodGet, "http://localhost:3030/address?wallet=2&change=external&id=1", nil, nil, http.StatusOK, "", "0xf4cefc8d1afaa51d5a5e7f57d214b60429ca4378"},
+		{"address_0", http.MethodGet, "http://localhost:3030/address?wallet=2&change=external&id=1", nil, nil, http.StatusOK, "", "0xF4cEFC8d1AfaA51d5A5E7f57d214B60429cA4378"},
 		{"address_1", http.MethodPost, "http://localhost:3030/address?wallet=2&change=external&id=1", nil, nil, http.StatusMethodNotAllowed, "", ""},
 		{"address_2", http.MethodGet, "http://localhost:3030/address?wallet=2&id=1", nil, nil, http.StatusBadRequest, ErrBadrequest.Error(), ""},
 		{"listen_0", http.MethodGet, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed", nil, nil, http.StatusBadRequest, ErrMissingNet.Error(), ""},
@@ -107,19 +114,44 @@ func TestAPI(t *testing.T) {
 		{"listen_2", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten&net=rinkeby", nil, nil, http.StatusBadRequest, ErrMissingNet.Error(), ""},
 		{"listen_3", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", nil, nil, http.StatusAccepted, "", ""},
 		{"listen_4", http.MethodDelete, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", nil, nil, http.StatusAccepted, "", ""},
+		{"listen_3a", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073?net=ropsten", nil, nil, http.StatusBadRequest, "invalid address: 0xcba75F167B03e34B8a572c50273C082401b073 has to be 0x followed by 40 hexadecimal digits", ""},
+		{"listen_3b", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=rinkeby", nil, nil, http.StatusBadRequest, ErrNoNet.Error(), ""},
+		{"listen_4g", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten&ttl=0", nil, nil, http.StatusBadRequest, ErrExpiry.Error(), ""},
+		{"listen_4h", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten&expires=1000", nil, nil, http.StatusBadRequest, ErrExpiry.Error(), ""},
+		{"listen_4i", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten&ttl=3600&notify=true", nil, nil, http.StatusAccepted, "", ""},
+		{"listen_4a", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", types.Sub{Owner: "user1", Dir: "sideways"}, nil, http.StatusBadRequest, ErrDir.Error(), ""},
+		{"listen_4b", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", "listen", nil, http.StatusBadRequest, ErrSub.Error(), ""},
+		{"listen_4e", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", types.Sub{Tokens: []string{"0x1234"}}, nil, http.StatusBadRequest, ErrToken.Error(), ""},
+		{"listen_4f", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", types.Sub{Min: "1000"}, nil, http.StatusBadRequest, ErrMin.Error(), ""},
+		{"listen_4c", http.MethodPost, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", types.Sub{Owner: "user1", Label: "deposits", Dir: types.DirIn, Meta: map[string]string{"order": "1"}, Tokens: []string{types.TokNative, "0xa34de7bd2b4270c0b12d5fd7a0c219a4d68d732f"}, Min: "0x038d7ea4c68000"}, nil, http.StatusAccepted, "", ""},
+		{"listen_4d", http.MethodDelete, "http://localhost:3030/listen/0xcba75F167B03e34B8a572c50273C082401b073Ed?net=ropsten", nil, nil, http.StatusAccepted, "", ""},
+		{"listen_5", http.MethodPost, "http://localhost:3030/listen/tx/0x123456?net=ropsten", nil, nil, http.StatusBadRequest, ErrNoHash.Error(), ""},
+		{"listen_6", http.MethodPost, "http://localhost:3030/listen/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872", nil, nil, http.StatusBadRequest, ErrMissingNet.Error(), ""},
+		{"listen_6a", http.MethodPost, "http://localhost:3030/listen/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=rinkeby", nil, nil, http.StatusBadRequest, ErrNoNet.Error(), ""},
+		{"listen_7", http.MethodPost, "http://localhost:3030/listen/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=ropsten&timeout=x", nil, nil, http.StatusBadRequest, ErrTimeout.Error(), ""},
+		{"listen_8", http.MethodPost, "http://localhost:3030/listen/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=ropsten&timeout=600", nil, nil, http.StatusAccepted, "", ""},
+		{"listen_9", http.MethodDelete, "http://localhost:3030/listen/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=ropsten", nil, nil, http.StatusAccepted, "", ""},
+		{"control_0", http.MethodGet, "http://localhost:3030/control/pause?net=ropsten", nil, nil, http.StatusMethodNotAllowed, "", ""},
+		{"control_1", http.MethodPost, "http://localhost:3030/control/pause", nil, nil, http.StatusBadRequest, ErrMissingNet.Error(), ""},
+		{"control_2", http.MethodPost, "http://localhost:3030/control/pause?net=rinkeby", nil, nil, http.StatusBadRequest, ErrNoNet.Error(), ""},
+		{"control_3", http.MethodPost, "http://localhost:3030/control/jump?net=ropsten", nil, nil, http.StatusBadRequest, ErrCommand.Error(), ""},
+		{"control_4", http.MethodPost, "http://localhost:3030/control/rewind?net=ropsten&from=x", nil, nil, http.StatusBadRequest, ErrBlocks.Error(), ""},
+		{"control_5", http.MethodPost, "http://localhost:3030/control/rescan?net=ropsten&from=10&to=5", nil, nil, http.StatusBadRequest, ErrBlocks.Error(), ""},
+		{"control_6", http.MethodPost, "http://localhost:3030/control/pause?net=ropsten", nil, nil, http.StatusAccepted, "", ""},
+		{"control_7", http.MethodPost, "http://localhost:3030/control/resume?net=ropsten", nil, nil, http.StatusAccepted, "", ""},
 		{"getAdr_0", http.MethodPost, "http://localhost:3030/listen", nil, nil, http.StatusMethodNotAllowed, "", ""},
 		{"getAdr_1", http.MethodGet, "http://localhost:3030/listen?net=mainNet", nil, nil, http.StatusAccepted, "", []store.ListenedAddresses{}},
 		{"getAdr_2", http.MethodGet, "http://localhost:3030/listen", nil, nil, http.StatusAccepted, "", []store.ListenedAddresses{{Net: "ropsten", Addr: []store.Address{}}}},
 		{"getAdr_3", http.MethodGet, "http://localhost:3030/listen?net=ropsten", nil, nil, http.StatusAccepted, "", []store.ListenedAddresses{{Net: "ropsten", Addr: []store.Address{}}}},
 		{"send_0", http.MethodPut, "http://localhost:3030/send", nil, nil, http.StatusMethodNotAllowed, "", ""},
 		{"send_1", http.MethodPost, "http://localhost:3030/send", TxReq{Net: "rinkeby"}, nil, http.StatusNotFound, "network not available", types.Trans{}},
-		{"send_2", http.MethodPost, "http://localhost:3030/send", TxReq{Net: "ropsten", Wallet: 2, Change: 0, ID: 1, Tx: types.Trans{To: "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", Value: "0x565656"}}, nil, http.StatusAccepted, "", types.Trans{To: "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", Value: "0x565656", Hash: "0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872", Status: 0, From: "0xf4cefc8d1afaa51d5a5e7f57d214b60429ca4378"}},
+		{"send_2", http.MethodPost, "http://localhost:3030/send", TxReq{Net: "ropsten", Wallet: 2, Change: 0, ID: 1, Tx: types.Trans{To: "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", Value: "0x565656"}}, nil, http.StatusAccepted, "", types.Trans{To: "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", Value: "0x565656", Hash: "0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872", Status: 0, From: "0xF4cEFC8d1AfaA51d5A5E7f57d214B60429cA4378"}},
 		{"tx_0", http.MethodPut, "http://localhost:3030/tx/0x123456", nil, nil, http.StatusMethodNotAllowed, "", types.Trans{}},
 		{"tx_1", http.MethodGet, "http://localhost:3030/tx/0x123456", nil, nil, http.StatusBadRequest, ErrNoHash.Error(), types.Trans{}},
 		{"tx_2", http.MethodGet, "http://localhost:3030/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872", nil, nil, http.StatusBadRequest, ErrNoNet.Error(), types.Trans{}},
 		{"tx_3", http.MethodGet, "http://localhost:3030/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=mainNet", nil, nil, http.StatusBadRequest, ErrNoNet.Error(), types.Trans{}},
 		{"tx_4", http.MethodGet, "http://localhost:3030/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=ropsten", nil, nil, http.StatusBadRequest, "cannot get transaction for hash 0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872: hash of transaction does not match with requested hash", types.Trans{}},
-		{"tx_5", http.MethodGet, "http://localhost:3030/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=ropsten", nil, nil, http.StatusOK, "", types.Trans{To: "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", Value: "0x565656", Hash: "0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872", Status: 0, From: "0xf4cefc8d1afaa51d5a5e7f57d214b60429ca4378"}},
+		{"tx_5", http.MethodGet, "http://localhost:3030/tx/0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872?net=ropsten", nil, nil, http.StatusOK, "", types.Trans{To: "0x357dd3856d856197c1a000bbAb4aBCB97Dfc92c4", Value: "0x565656", Hash: "0x2ba030485e79b5a98275b45d940e6fdd07b40dea593ef3b2a69b0a02a68a5872", Status: 0, From: "0xF4cEFC8d1AfaA51d5A5E7f57d214B60429cA4378"}},
 	}
 
 	// run tests